@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"github.com/chaseisabelle/md"
-	"github.com/chaseisabelle/md/mderr"
 	"github.com/chaseisabelle/md/mdhttp"
 	"github.com/chaseisabelle/md/mdlog"
 	"github.com/chaseisabelle/md/mdlog/mdzero"
@@ -36,7 +35,7 @@ func main() {
 	logger = mdlog.WithRequestID(logger, "")
 	logger = mdlog.WithErrorTrace(logger, "")
 
-	hf := handler
+	hf := mdhttp.Adapt(handler, logger, mdhttp.HandlerConfig{})
 
 	hf = mdhttp.ResponseLoggerMiddleware(hf, logger)
 	hf = mdhttp.RequestLoggerMiddleware(hf, logger)
@@ -71,13 +70,11 @@ func main() {
 	}
 }
 
-func handler(w http.ResponseWriter, r *http.Request) {
+func handler(w http.ResponseWriter, r *http.Request) error {
 	logger.Info(r.Context(), "handling request", md.MD{
 		"foo": "bar",
 	})
 
-	w.WriteHeader(http.StatusInternalServerError)
-
 	var err error
 
 	err = fmt.Errorf("root error")
@@ -86,19 +83,8 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		"foo": "bar",
 	})
 
-	err = md.W(err, "surface error", md.MD{
-		"pee": "poo",
-	})
-
-	logger.Error(r.Context(), err, md.MD{
-		"poop": "plop",
+	return md.W(err, "surface error", md.MD{
+		"pee":    "poo",
+		"status": http.StatusInternalServerError,
 	})
-
-	i, err := w.Write([]byte(mderr.Message(err)))
-
-	if err != nil {
-		logger.Error(r.Context(), md.W(err, "failed to write response body", md.MD{
-			"wrote": i,
-		}), nil)
-	}
 }
@@ -0,0 +1,80 @@
+package md
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// LazyValue is a metadata value that is computed when it is used
+// see Lazy
+type LazyValue struct {
+	once sync.Once
+	f    func() any
+	val  any
+}
+
+// Lazy creates a metadata value that is only computed when the
+// log entry is actually written, and at most once
+// useful for expensive values in entries that may be filtered out, ie
+//
+//	logger.Debug(ctx, "request body", md.MD{
+//		"body": md.Lazy(func() any { return dump(req) }),
+//	})
+func Lazy(f func() any) *LazyValue {
+	return &LazyValue{
+		f: f,
+	}
+}
+
+// Value computes the value, if it hasn't already been computed
+func (l *LazyValue) Value() any {
+	l.once.Do(func() {
+		if l.f != nil {
+			l.val = l.f()
+		}
+	})
+
+	return l.val
+}
+
+// String implements fmt.Stringer for non-json encoders
+func (l *LazyValue) String() string {
+	return fmt.Sprint(l.Value())
+}
+
+// MarshalJSON implements json.Marshaler so json encoders compute the
+// value when the entry is written
+func (l *LazyValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Value())
+}
+
+// Resolve gets a copy of the metadata with all the lazy values computed,
+// including lazy values in nested metadata
+// for encoders that don't use json.Marshaler
+func Resolve(md map[string]any) map[string]any {
+	if md == nil {
+		return nil
+	}
+
+	res := make(map[string]any, len(md))
+
+	for key, val := range md {
+		res[key] = resolve(val)
+	}
+
+	return res
+}
+
+func resolve(val any) any {
+	switch v := val.(type) {
+	case *LazyValue:
+		return resolve(v.Value())
+	case map[string]any:
+		return Resolve(v)
+	case MD:
+		return MD(Resolve(v))
+	default:
+		return val
+	}
+}
@@ -0,0 +1,34 @@
+package md_test
+
+import (
+	"encoding/json"
+	"github.com/chaseisabelle/md"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	cnt := 0
+	lzy := md.Lazy(func() any {
+		cnt++
+
+		return "bar"
+	})
+
+	mmd := md.MD{
+		"foo": lzy,
+		"nested": map[string]any{
+			"foo": lzy,
+		},
+	}
+
+	assert.Equal(t, 0, cnt)
+
+	buf, err := json.Marshal(mmd)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"foo":"bar","nested":{"foo":"bar"}}`, string(buf))
+	assert.Equal(t, map[string]any{"foo": "bar", "nested": map[string]any{"foo": "bar"}}, md.Resolve(mmd))
+	assert.Equal(t, "bar", lzy.String())
+	assert.Equal(t, 1, cnt)
+}
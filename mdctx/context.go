@@ -8,6 +8,7 @@ type Key int
 
 const (
 	RequestIDKey Key = iota
+	LevelKey
 )
 
 // WithRequestID sets a request id in the context
@@ -41,3 +42,26 @@ func RequestID(ctx context.Context) string {
 
 	return rid
 }
+
+// WithLevel sets a log level override in the context
+// the level is the level's name, ie "debug", so it can be parsed by mdlog
+// if level is empty, nothing is set and original context is returned
+func WithLevel(ctx context.Context, lvl string) context.Context {
+	if lvl == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, LevelKey, lvl)
+}
+
+// Level gets the log level override from a context
+// empty string if no level override set
+func Level(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	lvl, _ := ctx.Value(LevelKey).(string)
+
+	return lvl
+}
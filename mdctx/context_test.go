@@ -16,3 +16,14 @@ func TestRequestID(t *testing.T) {
 
 	assert.Equal(t, rid, mdctx.RequestID(ctx))
 }
+
+func TestLevel(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, "", mdctx.Level(ctx))
+	assert.Equal(t, ctx, mdctx.WithLevel(ctx, ""))
+
+	ctx = mdctx.WithLevel(ctx, "debug")
+
+	assert.Equal(t, "debug", mdctx.Level(ctx))
+}
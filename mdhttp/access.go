@@ -0,0 +1,162 @@
+package mdhttp
+
+import (
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mdlog"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AccessLogConfig configures AccessLog
+type AccessLogConfig struct {
+	// Message is the entry's message, defaults to "http request"
+	Message string
+	// Levels are the entry levels by status class, ie 4 for 4xx
+	// defaults to info for 1xx, 2xx and 3xx, warn for 4xx and error for 5xx
+	// classes not in the map use the defaults
+	Levels map[int]mdlog.Level
+	// Route gets the request's route, ie "/users/{id}", defaults to the url path
+	// use it to keep the route's cardinality low when paths have ids in them
+	Route func(*http.Request) string
+	// RequestIDKey is the request id header, defaults to "X-Request-ID"
+	// it's only used when the context has no request id, see RequestIDMiddleware
+	RequestIDKey string
+	// SkipPaths are url paths that aren't logged, ie "/health"
+	SkipPaths []string
+	// Skip checks if a request isn't logged, after the response is written
+	Skip func(r *http.Request, sts int) bool
+}
+
+// AccessLog logs one entry per request, after the response is written, with
+//
+//	method, route, status, duration-ms, bytes-in, bytes-out, remote-addr, user-agent, request-id
+//
+// the request id is read from the context or the request id header, see RequestIDMiddleware
+// entries the logger wouldn't write at the status class's level aren't built
+// if the handler panics, the entry is logged with a 500 status, unless one was
+// written, and the panic carries on to the Recover middleware, if any
+func AccessLog(hf http.HandlerFunc, lgr mdlog.Logger, cfg AccessLogConfig) http.HandlerFunc {
+	if cfg.Message == "" {
+		cfg.Message = "http request"
+	}
+
+	if cfg.RequestIDKey == "" {
+		cfg.RequestIDKey = "X-Request-ID"
+	}
+
+	lvs := map[int]mdlog.Level{
+		1: mdlog.Info,
+		2: mdlog.Info,
+		3: mdlog.Info,
+		4: mdlog.Warn,
+		5: mdlog.Error,
+	}
+
+	for cls, lvl := range cfg.Levels {
+		lvs[cls] = lvl
+	}
+
+	cfg.Levels = lvs
+
+	skp := make(map[string]bool, len(cfg.SkipPaths))
+
+	for _, pth := range cfg.SkipPaths {
+		skp[pth] = true
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if skp[r.URL.Path] {
+			hf(w, r)
+
+			return
+		}
+
+		bdy := &counter{
+			ReadCloser: r.Body,
+		}
+
+		if r.Body != nil {
+			r.Body = bdy
+		}
+
+		rec := newRecorder(w, 0)
+		now := time.Now()
+		don := false
+
+		defer func() {
+			sts := rec.Status()
+
+			if !don && !rec.Written() {
+				sts = http.StatusInternalServerError
+			}
+
+			cfg.log(lgr, r, sts, now, bdy.bytes, rec.Bytes())
+		}()
+
+		hf(rec.Writer(), r)
+
+		don = true
+	}
+}
+
+// log logs the entry for a request
+func (c AccessLogConfig) log(lgr mdlog.Logger, r *http.Request, sts int, now time.Time, bin int64, bot int64) {
+	if c.Skip != nil && c.Skip(r, sts) {
+		return
+	}
+
+	ctx := r.Context()
+	lvl, ok := c.Levels[sts/100]
+
+	if !ok {
+		lvl = mdlog.Error
+	}
+
+	if !mdlog.Enabled(lgr, ctx, lvl) {
+		return
+	}
+
+	rte := r.URL.Path
+
+	if c.Route != nil {
+		rte = c.Route(r)
+	}
+
+	md := map[string]any{
+		"method":      r.Method,
+		"route":       rte,
+		"status":      sts,
+		"duration-ms": float64(time.Since(now).Microseconds()) / 1000,
+		"bytes-in":    bin,
+		"bytes-out":   bot,
+		"remote-addr": r.RemoteAddr,
+		"user-agent":  r.UserAgent(),
+	}
+
+	rid := mdctx.RequestID(ctx)
+
+	if rid == "" {
+		rid = r.Header.Get(c.RequestIDKey)
+	}
+
+	if rid != "" {
+		md["request-id"] = rid
+	}
+
+	mdlog.Route(lgr, ctx, lvl, nil, c.Message, md)
+}
+
+// counter counts the bytes read from a request body
+type counter struct {
+	io.ReadCloser
+	bytes int64
+}
+
+func (c *counter) Read(buf []byte) (int, error) {
+	cnt, err := c.ReadCloser.Read(buf)
+
+	c.bytes += int64(cnt)
+
+	return cnt, err
+}
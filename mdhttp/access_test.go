@@ -0,0 +1,100 @@
+package mdhttp_test
+
+import (
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	tst := &testLogger{}
+
+	hf := mdhttp.RequestIDMiddleware(mdhttp.AccessLog(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		_, _ = w.Write([]byte("hello"))
+	}, tst, mdhttp.AccessLogConfig{
+		Levels: map[int]mdlog.Level{
+			4: mdlog.Info,
+		},
+		Route: func(r *http.Request) string {
+			return "/route" + r.URL.Path
+		},
+		SkipPaths: []string{"/health"},
+		Skip: func(r *http.Request, sts int) bool {
+			return r.URL.Path == "/ready" && sts == http.StatusOK
+		},
+	}), "")
+
+	req := httptest.NewRequest(http.MethodPost, "/ok", strings.NewReader("body"))
+
+	req.Header.Set("X-Request-ID", "rid")
+	req.Header.Set("User-Agent", "test")
+
+	hf(httptest.NewRecorder(), req)
+	hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	ens := tst.Entries()
+
+	assert.Len(t, ens, 3)
+
+	md := ens[0].md
+
+	assert.Equal(t, mdlog.Info, ens[0].level)
+	assert.Equal(t, "http request", ens[0].msg)
+	assert.Equal(t, http.MethodPost, md["method"])
+	assert.Equal(t, "/route/ok", md["route"])
+	assert.Equal(t, http.StatusOK, md["status"])
+	assert.Equal(t, int64(4), md["bytes-in"])
+	assert.Equal(t, int64(5), md["bytes-out"])
+	assert.Equal(t, "192.0.2.1:1234", md["remote-addr"])
+	assert.Equal(t, "test", md["user-agent"])
+	assert.Equal(t, "rid", md["request-id"])
+	assert.IsType(t, float64(0), md["duration-ms"])
+
+	assert.Equal(t, mdlog.Info, ens[1].level)
+	assert.Equal(t, http.StatusNotFound, ens[1].md["status"])
+	assert.NotEmpty(t, ens[1].md["request-id"])
+
+	assert.Equal(t, mdlog.Error, ens[2].level)
+	assert.EqualError(t, ens[2].err, "http request")
+	assert.Equal(t, http.StatusInternalServerError, ens[2].md["status"])
+}
+
+func TestAccessLogPanic(t *testing.T) {
+	tst := &testLogger{}
+
+	hf := mdhttp.Recover(tst)(mdhttp.AccessLog(func(w http.ResponseWriter, r *http.Request) {
+		panic("oh no")
+	}, tst, mdhttp.AccessLogConfig{}))
+
+	res := httptest.NewRecorder()
+
+	hf(res, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	ens := tst.Entries()
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Len(t, ens, 2)
+	assert.Equal(t, mdlog.Error, ens[0].level)
+	assert.Equal(t, "http request", ens[0].err.Error())
+	assert.Equal(t, http.StatusInternalServerError, ens[0].md["status"])
+	assert.Equal(t, "recovered panic", ens[1].err.Error())
+	assert.Equal(t, "oh no", mderr.Metadata(ens[1].err)["panic"])
+}
@@ -0,0 +1,133 @@
+package mdhttp
+
+import (
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"net/http"
+	"strconv"
+)
+
+// Handler is a http handler that returns its error instead of writing it, see Adapt
+type Handler func(http.ResponseWriter, *http.Request) error
+
+// Renderer writes the response for a handler's error with the status
+type Renderer func(w http.ResponseWriter, r *http.Request, sts int, err error)
+
+// HandlerConfig configures Adapt
+type HandlerConfig struct {
+	// StatusKey is the error metadata key a status is read from, defaults to "status", ie
+	//
+	//	mderr.New("user not found", map[string]any{"status": http.StatusNotFound})
+	StatusKey string
+	// Codes maps error codes to statuses, ie {"not-found": http.StatusNotFound}
+	// codes are read from the error metadata and compared as strings
+	Codes map[string]int
+	// CodeKey is the error metadata key a code is read from, defaults to "code"
+	CodeKey string
+	// Renderer writes the error response, defaults to TextRenderer
+	Renderer Renderer
+}
+
+// Adapt makes a http.HandlerFunc from a Handler
+// a returned error is logged once as an error entry with the request's method,
+// url, request id and the status, and rendered with the status from the
+// error's metadata, see HandlerConfig, or 500 if it has none
+// the error isn't rendered if the handler already wrote a status
+// use mdlog.WithErrorClassifier to log client errors at a lower level
+func Adapt(hnd Handler, lgr mdlog.Logger, cfg HandlerConfig) http.HandlerFunc {
+	if cfg.StatusKey == "" {
+		cfg.StatusKey = "status"
+	}
+
+	if cfg.CodeKey == "" {
+		cfg.CodeKey = "code"
+	}
+
+	if cfg.Renderer == nil {
+		cfg.Renderer = TextRenderer
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := newRecorder(w, 0)
+		err := hnd(rec.Writer(), r)
+
+		if err == nil {
+			return
+		}
+
+		sts := cfg.status(err)
+		md := requestMetadata(r)
+
+		md["status"] = sts
+
+		lgr.Error(r.Context(), err, md)
+
+		if !rec.Written() {
+			cfg.Renderer(rec, r, sts, err)
+		}
+	}
+}
+
+// status gets the status for an error
+// the outermost error in the stack with a status or a mapped code wins
+func (c HandlerConfig) status(err error) int {
+	for _, cur := range mderr.Array(err) {
+		md := mderr.Metadata(cur)
+
+		if sts, ok := statusValue(md[c.StatusKey]); ok {
+			return sts
+		}
+
+		if cod, ok := md[c.CodeKey]; ok {
+			if sts, ok := c.Codes[fmt.Sprint(cod)]; ok {
+				return sts
+			}
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// statusValue gets a status from a metadata value, ie 404 or "404"
+func statusValue(val any) (int, bool) {
+	var sts int
+
+	switch v := val.(type) {
+	case int:
+		sts = v
+	case int32:
+		sts = int(v)
+	case int64:
+		sts = int(v)
+	case string:
+		cnv, err := strconv.Atoi(v)
+
+		if err != nil {
+			return 0, false
+		}
+
+		sts = cnv
+	default:
+		return 0, false
+	}
+
+	if sts < 100 || sts > 599 {
+		return 0, false
+	}
+
+	return sts, true
+}
+
+// TextRenderer writes the error as plain text
+// 4xx responses get the error's message, see mderr.Message, and 5xx responses
+// get the status text, so internal errors aren't leaked to clients
+func TextRenderer(w http.ResponseWriter, _ *http.Request, sts int, err error) {
+	msg := http.StatusText(sts)
+
+	if sts < http.StatusInternalServerError {
+		msg = mderr.Message(err)
+	}
+
+	http.Error(w, msg, sts)
+}
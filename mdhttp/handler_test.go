@@ -0,0 +1,89 @@
+package mdhttp_test
+
+import (
+	"errors"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdapt(t *testing.T) {
+	tst := &testLogger{}
+
+	hf := mdhttp.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte("ok"))
+
+			return nil
+		case "/status":
+			return mderr.Wrap(mderr.New("user not found", map[string]any{
+				"status": "500",
+			}), "no user", map[string]any{
+				"status": http.StatusNotFound,
+			})
+		case "/code":
+			return mderr.New("slow down", map[string]any{
+				"code": "rate-limited",
+			})
+		case "/written":
+			w.WriteHeader(http.StatusAccepted)
+		}
+
+		return errors.New("internal")
+	}, tst, mdhttp.HandlerConfig{
+		Codes: map[string]int{
+			"rate-limited": http.StatusTooManyRequests,
+		},
+	})
+
+	tcs := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/ok", http.StatusOK, "ok"},
+		{"/status", http.StatusNotFound, "no user\n"},
+		{"/code", http.StatusTooManyRequests, "slow down\n"},
+		{"/written", http.StatusAccepted, ""},
+		{"/fail", http.StatusInternalServerError, "Internal Server Error\n"},
+	}
+
+	for _, tc := range tcs {
+		rec := httptest.NewRecorder()
+
+		hf(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		assert.Equal(t, tc.status, rec.Code, tc.path)
+		assert.Equal(t, tc.body, rec.Body.String(), tc.path)
+	}
+
+	ens := tst.Entries()
+
+	assert.Len(t, ens, 4)
+	assert.Equal(t, http.StatusNotFound, ens[0].md["status"])
+	assert.Equal(t, "/status", ens[0].md["url"])
+	assert.EqualError(t, ens[3].err, "internal")
+}
+
+func TestAdaptRenderer(t *testing.T) {
+	hf := mdhttp.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("internal")
+	}, &testLogger{}, mdhttp.HandlerConfig{
+		Renderer: func(w http.ResponseWriter, r *http.Request, sts int, err error) {
+			w.WriteHeader(http.StatusTeapot)
+
+			_, _ = w.Write([]byte(err.Error()))
+		},
+	})
+
+	rec := httptest.NewRecorder()
+
+	hf(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "internal", rec.Body.String())
+}
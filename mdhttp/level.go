@@ -0,0 +1,124 @@
+package mdhttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/chaseisabelle/md/mdlog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LevelConfig configures LevelMiddleware
+type LevelConfig struct {
+	// Header is the request header the level is read from, defaults to "X-Debug-Log"
+	Header string
+	// Levels are the levels a request can ask for, defaults to debug and trace
+	Levels []mdlog.Level
+	// Secret is the key the header's value must be signed with, see SignLevel
+	// if empty, the header is ignored unless Insecure is set
+	Secret []byte
+	// Insecure accepts unsigned header values when there's no Secret,
+	// the value is just the level's name, ie "debug", and anyone who can
+	// reach the server can turn on the override, so only use it in development
+	Insecure bool
+}
+
+// LevelMiddleware reads a log level from the request header and sets it
+// as the request context's level override, see mdlog.LevelContext
+// so only that request logs at the more verbose level
+// headers asking for a level not in the allow list, or with a bad or
+// expired signature, are ignored, and so are all headers if there's
+// no Secret and Insecure isn't set
+func LevelMiddleware(hf http.HandlerFunc, cfg LevelConfig) http.HandlerFunc {
+	if cfg.Header == "" {
+		cfg.Header = "X-Debug-Log"
+	}
+
+	if len(cfg.Levels) == 0 {
+		cfg.Levels = []mdlog.Level{mdlog.Debug, mdlog.Trace}
+	}
+
+	if len(cfg.Secret) == 0 && !cfg.Insecure {
+		return hf
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		val := r.Header.Get(cfg.Header)
+
+		if val == "" {
+			hf(w, r)
+
+			return
+		}
+
+		lvl, ok := cfg.level(val, time.Now())
+
+		if ok {
+			r = r.WithContext(mdlog.LevelContext(r.Context(), lvl))
+		}
+
+		hf(w, r)
+	}
+}
+
+// SignLevel makes a signed header value for LevelMiddleware
+// the value is "<level>.<expiry unix seconds>.<hex hmac-sha256>"
+// and is only accepted until it expires
+func SignLevel(sec []byte, lvl mdlog.Level, exp time.Time) string {
+	pfx := lvl.String() + "." + strconv.FormatInt(exp.Unix(), 10)
+
+	return pfx + "." + signature(sec, pfx)
+}
+
+// level gets the allowed level from a header value
+func (c LevelConfig) level(val string, now time.Time) (mdlog.Level, bool) {
+	nam := val
+
+	if len(c.Secret) > 0 {
+		idx := strings.LastIndex(val, ".")
+
+		if idx < 0 || !hmac.Equal([]byte(val[idx+1:]), []byte(signature(c.Secret, val[:idx]))) {
+			return 0, false
+		}
+
+		pfx := val[:idx]
+		idx = strings.LastIndex(pfx, ".")
+
+		if idx < 0 {
+			return 0, false
+		}
+
+		exp, err := strconv.ParseInt(pfx[idx+1:], 10, 64)
+
+		if err != nil || now.Unix() > exp {
+			return 0, false
+		}
+
+		nam = pfx[:idx]
+	}
+
+	lvl, err := mdlog.ParseLevel(nam)
+
+	if err != nil {
+		return 0, false
+	}
+
+	for _, alw := range c.Levels {
+		if lvl == alw {
+			return lvl, true
+		}
+	}
+
+	return 0, false
+}
+
+func signature(sec []byte, val string) string {
+	mac := hmac.New(sha256.New, sec)
+
+	_, _ = mac.Write([]byte(val))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,77 @@
+package mdhttp_test
+
+import (
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLevelMiddleware(t *testing.T) {
+	sec := []byte("secret")
+	var act mdlog.Level
+	var set bool
+
+	hf := mdhttp.LevelMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		act, set = mdlog.ContextLevel(r.Context())
+	}, mdhttp.LevelConfig{
+		Secret: sec,
+	})
+
+	tests := map[string]struct {
+		value string
+		level mdlog.Level
+		set   bool
+	}{
+		"none":       {"", 0, false},
+		"unsigned":   {"debug", 0, false},
+		"signed":     {mdhttp.SignLevel(sec, mdlog.Debug, time.Now().Add(time.Minute)), mdlog.Debug, true},
+		"expired":    {mdhttp.SignLevel(sec, mdlog.Debug, time.Now().Add(-time.Minute)), 0, false},
+		"bad secret": {mdhttp.SignLevel([]byte("nope"), mdlog.Debug, time.Now().Add(time.Minute)), 0, false},
+		"not listed": {mdhttp.SignLevel(sec, mdlog.Info, time.Now().Add(time.Minute)), 0, false},
+	}
+
+	for nam, tst := range tests {
+		t.Run(nam, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			req.Header.Set("X-Debug-Log", tst.value)
+
+			hf(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tst.set, set)
+			assert.Equal(t, tst.level, act)
+		})
+	}
+}
+
+func TestLevelMiddlewareInsecure(t *testing.T) {
+	tests := map[string]struct {
+		config mdhttp.LevelConfig
+		set    bool
+	}{
+		"no secret": {mdhttp.LevelConfig{}, false},
+		"insecure":  {mdhttp.LevelConfig{Insecure: true}, true},
+	}
+
+	for nam, tst := range tests {
+		t.Run(nam, func(t *testing.T) {
+			var set bool
+
+			hf := mdhttp.LevelMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				_, set = mdlog.ContextLevel(r.Context())
+			}, tst.config)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			req.Header.Set("X-Debug-Log", "debug")
+
+			hf(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tst.set, set)
+		})
+	}
+}
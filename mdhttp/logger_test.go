@@ -0,0 +1,74 @@
+package mdhttp_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdlog"
+	"sync"
+)
+
+// entry is a log entry written to a testLogger
+type entry struct {
+	ctx   context.Context
+	level mdlog.Level
+	err   error
+	msg   string
+	md    map[string]any
+}
+
+// testLogger records its entries
+type testLogger struct {
+	mutex   sync.Mutex
+	entries []*entry
+}
+
+func (t *testLogger) add(ctx context.Context, lvl mdlog.Level, err error, msg string, md map[string]any) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.entries = append(t.entries, &entry{
+		ctx:   ctx,
+		level: lvl,
+		err:   err,
+		msg:   msg,
+		md:    md,
+	})
+}
+
+func (t *testLogger) Entries() []*entry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return append([]*entry{}, t.entries...)
+}
+
+func (t *testLogger) Fatal(ctx context.Context, err error, md map[string]any) {
+	t.add(ctx, mdlog.Fatal, err, "", md)
+}
+
+func (t *testLogger) Panic(ctx context.Context, err error, md map[string]any) {
+	t.add(ctx, mdlog.Panic, err, "", md)
+}
+
+func (t *testLogger) Error(ctx context.Context, err error, md map[string]any) {
+	t.add(ctx, mdlog.Error, err, "", md)
+}
+
+func (t *testLogger) Warn(ctx context.Context, msg string, md map[string]any) {
+	t.add(ctx, mdlog.Warn, nil, msg, md)
+}
+
+func (t *testLogger) Info(ctx context.Context, msg string, md map[string]any) {
+	t.add(ctx, mdlog.Info, nil, msg, md)
+}
+
+func (t *testLogger) Debug(ctx context.Context, msg string, md map[string]any) {
+	t.add(ctx, mdlog.Debug, nil, msg, md)
+}
+
+func (t *testLogger) Trace(ctx context.Context, msg string, md map[string]any) {
+	t.add(ctx, mdlog.Trace, nil, msg, md)
+}
+
+func (t *testLogger) At(ctx context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+	t.add(ctx, lvl, nil, msg, md)
+}
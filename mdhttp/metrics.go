@@ -0,0 +1,73 @@
+package mdhttp
+
+import (
+	"fmt"
+	"github.com/chaseisabelle/md/mdlog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MetricsHandler exposes the log metrics in the prometheus text format
+//
+//	md_log_entries_total{level="error"} 3
+//	md_log_errors_total{error="connection refused"} 2
+//	md_log_label_entries_total{label="env",value="prod"} 10
+func MetricsHandler(mtr *mdlog.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snp := mtr.Snapshot()
+		buf := strings.Builder{}
+
+		buf.WriteString("# HELP md_log_entries_total Log entries by level.\n")
+		buf.WriteString("# TYPE md_log_entries_total counter\n")
+
+		for _, key := range keys(snp.Levels) {
+			buf.WriteString(fmt.Sprintf("md_log_entries_total{level=\"%s\"} %d\n", escape(key), snp.Levels[key]))
+		}
+
+		buf.WriteString("# HELP md_log_errors_total Error log entries by error code or root message.\n")
+		buf.WriteString("# TYPE md_log_errors_total counter\n")
+
+		for _, key := range keys(snp.Errors) {
+			buf.WriteString(fmt.Sprintf("md_log_errors_total{error=\"%s\"} %d\n", escape(key), snp.Errors[key]))
+		}
+
+		buf.WriteString("# HELP md_log_label_entries_total Log entries by metadata label.\n")
+		buf.WriteString("# TYPE md_log_label_entries_total counter\n")
+
+		lbs := make([]string, 0, len(snp.Labels))
+
+		for lbl := range snp.Labels {
+			lbs = append(lbs, lbl)
+		}
+
+		sort.Strings(lbs)
+
+		for _, lbl := range lbs {
+			for _, key := range keys(snp.Labels[lbl]) {
+				buf.WriteString(fmt.Sprintf("md_log_label_entries_total{label=\"%s\",value=\"%s\"} %d\n", escape(lbl), escape(key), snp.Labels[lbl][key]))
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		_, _ = w.Write([]byte(buf.String()))
+	}
+}
+
+func keys(cnt map[string]uint64) []string {
+	kys := make([]string, 0, len(cnt))
+
+	for key := range cnt {
+		kys = append(kys, key)
+	}
+
+	sort.Strings(kys)
+
+	return kys
+}
+
+// escape escapes a prometheus label value
+func escape(val string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(val)
+}
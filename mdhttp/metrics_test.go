@@ -0,0 +1,37 @@
+package mdhttp_test
+
+import (
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	mtr := mdlog.NewMetrics(mdlog.MetricsConfig{
+		Labels: []string{"env"},
+	})
+
+	mtr.Count(mdlog.Error, mderr.New(`bad "thing"`, nil), map[string]any{"env": "prod"})
+	mtr.Count(mdlog.Info, nil, map[string]any{"env": "prod"})
+
+	rec := httptest.NewRecorder()
+
+	mdhttp.MetricsHandler(mtr)(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `# HELP md_log_entries_total Log entries by level.
+# TYPE md_log_entries_total counter
+md_log_entries_total{level="error"} 1
+md_log_entries_total{level="info"} 1
+# HELP md_log_errors_total Error log entries by error code or root message.
+# TYPE md_log_errors_total counter
+md_log_errors_total{error="bad \"thing\""} 1
+# HELP md_log_label_entries_total Log entries by metadata label.
+# TYPE md_log_label_entries_total counter
+md_log_label_entries_total{label="env",value="prod"} 2
+`, rec.Body.String())
+}
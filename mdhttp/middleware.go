@@ -8,9 +8,12 @@ import (
 	"github.com/google/uuid"
 	"io"
 	"net/http"
-	"net/http/httptest"
+	"strconv"
 )
 
+// maxBody is the max number of response body bytes ResponseLoggerMiddleware logs
+const maxBody = 64 << 10
+
 // RequestIDMiddleware gets a request id from the headers and adds it to the context
 // if there is no request id header, it generates and sets one
 func RequestIDMiddleware(hf http.HandlerFunc, key string) http.HandlerFunc {
@@ -59,41 +62,56 @@ func RequestLoggerMiddleware(hf http.HandlerFunc, lgr mdlog.Logger) http.Handler
 }
 
 // ResponseLoggerMiddleware logs all outgoing responses
+// the response is passed through as it's written, so streaming, server-sent events
+// and websockets work, and up to 64KiB of the body is copied for the entry
 // probably don't use this in a prod env
 func ResponseLoggerMiddleware(hf http.HandlerFunc, lgr mdlog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rec := httptest.NewRecorder()
-
-		hf(rec, r)
+		rec := newRecorder(w, maxBody)
 
-		res := rec.Result()
+		hf(rec.Writer(), r)
 
 		md := map[string]any{
-			"status-code": res.StatusCode,
+			"status-code": rec.Status(),
+			"headers":     w.Header().Clone(),
 		}
 
-		w.WriteHeader(res.StatusCode)
+		bod := string(rec.Body())
 
-		if res.Body != nil {
-			bod, err := io.ReadAll(res.Body)
+		if rec.Bytes() > int64(len(bod)) {
+			bod += "…(+" + strconv.FormatInt(rec.Bytes()-int64(len(bod)), 10) + " bytes)"
+		}
 
-			if err != nil {
-				lgr.Error(r.Context(), mderr.Wrap(err, "failed to read response body", nil), md)
-			}
+		md["body"] = bod
 
-			if bod != nil {
-				md["body"] = string(bod)
-			}
+		lgr.Debug(r.Context(), "outgoing http response", md)
+	}
+}
+
+// BufferMiddleware buffers each request's debug, info and trace entries,
+// up to max entries, and only writes them if the request fails
+// the buffer is flushed by the first error entry, or when the response
+// status is 5xx or the handler panics, and discarded otherwise
+// the logger must have mdlog.WithBuffering applied
+func BufferMiddleware(hf http.HandlerFunc, max int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, buf := mdlog.BufferContext(r.Context(), max)
+		rec := newRecorder(w, 0)
 
-			bw, err := w.Write(bod)
+		defer func() {
+			pnc := recover()
 
-			if err != nil {
-				lgr.Error(r.Context(), mderr.Wrap(err, "failed to write back response body", map[string]any{
-					"wrote": bw,
-				}), nil)
+			if pnc != nil || rec.Status() >= http.StatusInternalServerError {
+				buf.Flush()
+			} else {
+				buf.Discard()
 			}
-		}
 
-		lgr.Debug(r.Context(), "outgoing http response", md)
+			if pnc != nil {
+				panic(pnc)
+			}
+		}()
+
+		hf(rec.Writer(), r.WithContext(ctx))
 	}
 }
@@ -0,0 +1,113 @@
+package mdhttp_test
+
+import (
+	"bufio"
+	"errors"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBufferMiddleware(t *testing.T) {
+	tst := &testLogger{}
+	lgr := mdlog.WithBuffering(tst)
+
+	hf := mdhttp.BufferMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		lgr.Debug(r.Context(), "debug", nil)
+		lgr.Info(r.Context(), "info", nil)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}, 10)
+
+	hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	assert.Empty(t, tst.Entries())
+
+	rec := httptest.NewRecorder()
+
+	hf(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	ens := tst.Entries()
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Len(t, ens, 2)
+	assert.Equal(t, "debug", ens[0].msg)
+	assert.Equal(t, "info", ens[1].msg)
+}
+
+func TestResponseLoggerMiddleware(t *testing.T) {
+	tst := &testLogger{}
+
+	hf := mdhttp.ResponseLoggerMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusAccepted)
+
+		_, _ = w.Write([]byte("data: one\n\n"))
+
+		flu, ok := w.(http.Flusher)
+
+		assert.True(t, ok)
+
+		flu.Flush()
+
+		_, ok = w.(http.Hijacker)
+
+		assert.False(t, ok)
+
+		_, _ = w.Write([]byte(strings.Repeat("x", 70000)))
+	}, tst)
+
+	rec := httptest.NewRecorder()
+
+	hf(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	ens := tst.Entries()
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 70011, rec.Body.Len())
+	assert.Len(t, ens, 1)
+	assert.Equal(t, http.StatusAccepted, ens[0].md["status-code"])
+	assert.Equal(t, "text/event-stream", ens[0].md["headers"].(http.Header).Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(ens[0].md["body"].(string), "data: one\n\nxxx"))
+	assert.True(t, strings.HasSuffix(ens[0].md["body"].(string), "x…(+4475 bytes)"))
+}
+
+// hijackWriter is a writer with a connection that can be hijacked
+type hijackWriter struct {
+	http.ResponseWriter
+}
+
+func (h hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijacked")
+}
+
+func TestResponseLoggerMiddlewareHijack(t *testing.T) {
+	tst := &testLogger{}
+
+	hf := mdhttp.ResponseLoggerMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+
+		assert.False(t, ok)
+
+		hij, ok := w.(http.Hijacker)
+
+		assert.True(t, ok)
+
+		_, _, err := hij.Hijack()
+
+		assert.EqualError(t, err, "hijacked")
+	}, tst)
+
+	hf(hijackWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Len(t, tst.Entries(), 1)
+}
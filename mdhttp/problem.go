@@ -0,0 +1,248 @@
+package mdhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ProblemContentType is the content type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object
+// extension members are written alongside the standard members
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// MarshalJSON encodes the problem with its extension members at the top level
+// empty standard members are left out
+func (p Problem) MarshalJSON() ([]byte, error) {
+	obj := make(map[string]any, len(p.Extensions)+5)
+
+	for key, val := range p.Extensions {
+		obj[key] = val
+	}
+
+	for key, val := range map[string]string{"type": p.Type, "title": p.Title, "detail": p.Detail, "instance": p.Instance} {
+		if val != "" {
+			obj[key] = val
+		}
+	}
+
+	if p.Status != 0 {
+		obj["status"] = p.Status
+	}
+
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON decodes the problem, members that aren't standard are extensions
+func (p *Problem) UnmarshalJSON(buf []byte) error {
+	obj := map[string]json.RawMessage{}
+
+	err := json.Unmarshal(buf, &obj)
+
+	if err != nil {
+		return err
+	}
+
+	*p = Problem{}
+
+	for key, raw := range obj {
+		var dst any
+
+		switch key {
+		case "type":
+			dst = &p.Type
+		case "title":
+			dst = &p.Title
+		case "status":
+			dst = &p.Status
+		case "detail":
+			dst = &p.Detail
+		case "instance":
+			dst = &p.Instance
+		default:
+			var val any
+
+			err = json.Unmarshal(raw, &val)
+
+			if err != nil {
+				return err
+			}
+
+			if p.Extensions == nil {
+				p.Extensions = map[string]any{}
+			}
+
+			p.Extensions[key] = val
+
+			continue
+		}
+
+		// members with the wrong type are ignored, as the rfc says
+		_ = json.Unmarshal(raw, dst)
+	}
+
+	return nil
+}
+
+// ProblemConfig configures ProblemRenderer
+type ProblemConfig struct {
+	// TypeKey is the error metadata key the problem type uri is read from,
+	// defaults to "type", and the type is "about:blank" if no error has one
+	TypeKey string
+	// Extensions are the error metadata keys written as extension members, ie "code"
+	// no other metadata is written, so internal metadata never leaks to clients
+	Extensions []string
+}
+
+// ProblemRenderer makes a Renderer that writes errors as RFC 7807 problem details, ie
+//
+//	{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","instance":"<request id>"}
+//
+// the detail is the error's message, see mderr.Message, and it's left out of 5xx
+// responses so internal errors aren't leaked to clients
+// metadata values are read from the outermost error in the stack that has them
+func ProblemRenderer(cfg ProblemConfig) Renderer {
+	if cfg.TypeKey == "" {
+		cfg.TypeKey = "type"
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, sts int, err error) {
+		prb := Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(sts),
+			Status:   sts,
+			Instance: mdctx.RequestID(r.Context()),
+		}
+
+		if sts < http.StatusInternalServerError {
+			prb.Detail = mderr.Message(err)
+		}
+
+		if typ, ok := lookup(err, cfg.TypeKey).(string); ok && typ != "" {
+			prb.Type = typ
+		}
+
+		for _, key := range cfg.Extensions {
+			val := lookup(err, key)
+
+			if val == nil {
+				continue
+			}
+
+			if prb.Extensions == nil {
+				prb.Extensions = map[string]any{}
+			}
+
+			prb.Extensions[key] = val
+		}
+
+		buf, err := json.Marshal(prb)
+
+		if err != nil {
+			// an extension couldn't be encoded, so the standard members are written without them
+			prb.Extensions = nil
+
+			buf, _ = json.Marshal(prb)
+		}
+
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(sts)
+
+		_, _ = w.Write(buf)
+	}
+}
+
+// lookup gets a metadata value from the outermost error in the stack that has it
+func lookup(err error, key string) any {
+	for _, cur := range mderr.Array(err) {
+		val, ok := mderr.Metadata(cur)[key]
+
+		if ok {
+			return val
+		}
+	}
+
+	return nil
+}
+
+// ParseProblem rebuilds an error from a problem details response
+// the error's message is the detail, or the title if there's no detail,
+// and its metadata has the type, title, status, instance and extension members,
+// so the status is kept when the error is returned from a Handler, see Adapt
+// the response body can still be read after it's parsed
+func ParseProblem(res *http.Response) (*mderr.MDErr, error) {
+	mdt, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+
+	if err != nil || mdt != ProblemContentType {
+		return nil, mderr.New("not a problem response", map[string]any{
+			"content-type": res.Header.Get("Content-Type"),
+			"status":       res.StatusCode,
+		})
+	}
+
+	buf, err := io.ReadAll(res.Body)
+
+	_ = res.Body.Close()
+
+	res.Body = io.NopCloser(bytes.NewReader(buf))
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to read problem response", nil)
+	}
+
+	prb := Problem{}
+
+	err = json.Unmarshal(buf, &prb)
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to decode problem response", map[string]any{
+			"body": string(buf),
+		})
+	}
+
+	if prb.Type == "" {
+		prb.Type = "about:blank"
+	}
+
+	if prb.Status == 0 {
+		prb.Status = res.StatusCode
+	}
+
+	md := make(map[string]any, len(prb.Extensions)+5)
+
+	for key, val := range prb.Extensions {
+		md[key] = val
+	}
+
+	md["type"] = prb.Type
+	md["title"] = prb.Title
+	md["status"] = prb.Status
+
+	if prb.Instance != "" {
+		md["instance"] = prb.Instance
+	}
+
+	msg := prb.Detail
+
+	if msg == "" {
+		msg = prb.Title
+	}
+
+	mde, _ := mderr.AsIs(mderr.New(msg, md))
+
+	return mde, nil
+}
@@ -0,0 +1,95 @@
+package mdhttp_test
+
+import (
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProblemRenderer(t *testing.T) {
+	hf := mdhttp.RequestIDMiddleware(mdhttp.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/fail" {
+			return mderr.New("database password is hunter2", nil)
+		}
+
+		return mderr.Wrap(mderr.New("no rows", map[string]any{
+			"query": "select * from users",
+		}), "user not found", map[string]any{
+			"status":  http.StatusNotFound,
+			"type":    "https://example.com/problems/not-found",
+			"code":    "user-not-found",
+			"user-id": 1234,
+		})
+	}, &testLogger{}, mdhttp.HandlerConfig{
+		Renderer: mdhttp.ProblemRenderer(mdhttp.ProblemConfig{
+			Extensions: []string{"code", "user-id"},
+		}),
+	}), "")
+
+	srv := httptest.NewServer(hf)
+
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users/1234", nil)
+
+	assert.NoError(t, err)
+
+	req.Header.Set("X-Request-ID", "rid")
+
+	res, err := http.DefaultClient.Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, mdhttp.ProblemContentType, res.Header.Get("Content-Type"))
+
+	mde, err := mdhttp.ParseProblem(res)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user not found", mde.Message())
+	assert.Equal(t, map[string]any{
+		"type":     "https://example.com/problems/not-found",
+		"title":    "Not Found",
+		"status":   http.StatusNotFound,
+		"instance": "rid",
+		"code":     "user-not-found",
+		"user-id":  float64(1234),
+	}, mde.Metadata())
+
+	bod, err := io.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(bod), "select")
+
+	res, err = http.Get(srv.URL + "/fail")
+
+	assert.NoError(t, err)
+
+	mde, err = mdhttp.ParseProblem(res)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Internal Server Error", mde.Message())
+	assert.Equal(t, "about:blank", mde.Metadata()["type"])
+	assert.Equal(t, http.StatusInternalServerError, mde.Metadata()["status"])
+
+	bod, err = io.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(bod), "hunter2")
+}
+
+func TestParseProblemNotProblem(t *testing.T) {
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}
+
+	_, err := mdhttp.ParseProblem(res)
+
+	assert.EqualError(t, err, "not a problem response")
+}
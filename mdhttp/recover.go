@@ -0,0 +1,75 @@
+package mdhttp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover makes middleware that recovers panics in handlers
+// the panic is logged as an error with the panic value and the goroutine's stack
+// in its metadata, and a 500 is written if the handler hasn't written a status
+// http.ErrAbortHandler panics aren't logged and are re-panicked, so the server
+// aborts the response the way the handler asked
+func Recover(lgr mdlog.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	return func(hf http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rec := newRecorder(w, 0)
+
+			defer func() {
+				pnc := recover()
+
+				if pnc == nil {
+					return
+				}
+
+				if err, ok := pnc.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(pnc)
+				}
+
+				lgr.Error(r.Context(), panicError(pnc, debug.Stack()), requestMetadata(r))
+
+				if !rec.Written() {
+					http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			hf(rec.Writer(), r)
+		}
+	}
+}
+
+// panicError makes an error from a recovered panic value
+// panics with errors wrap them, so the error's root is the panic's
+func panicError(pnc any, stk []byte) error {
+	md := map[string]any{
+		"panic": fmt.Sprint(pnc),
+		"stack": string(stk),
+	}
+
+	if err, ok := pnc.(error); ok {
+		return mderr.Wrap(err, "recovered panic", md)
+	}
+
+	return mderr.New("recovered panic", md)
+}
+
+// requestMetadata gets the metadata that identifies a request
+func requestMetadata(r *http.Request) map[string]any {
+	md := map[string]any{
+		"method": r.Method,
+		"url":    r.URL.String(),
+	}
+
+	rid := mdctx.RequestID(r.Context())
+
+	if rid != "" {
+		md["request-id"] = rid
+	}
+
+	return md
+}
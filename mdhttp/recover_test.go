@@ -0,0 +1,72 @@
+package mdhttp_test
+
+import (
+	"errors"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	tst := &testLogger{}
+	cse := errors.New("cause")
+
+	hf := mdhttp.RequestIDMiddleware(mdhttp.Recover(tst)(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/written":
+			w.WriteHeader(http.StatusAccepted)
+
+			panic("written")
+		case "/error":
+			panic(cse)
+		case "/abort":
+			panic(http.ErrAbortHandler)
+		}
+
+		panic("boom")
+	}), "")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+
+	req.Header.Set("X-Request-ID", "rid")
+
+	hf(rec, req)
+
+	ens := tst.Entries()
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Len(t, ens, 1)
+	assert.Equal(t, "rid", ens[0].md["request-id"])
+	assert.Equal(t, "/boom", ens[0].md["url"])
+
+	mde, ok := mderr.AsIs(ens[0].err)
+
+	assert.True(t, ok)
+	assert.Equal(t, "recovered panic", mde.Message())
+	assert.Equal(t, "boom", mde.Metadata()["panic"])
+	assert.Contains(t, mde.Metadata()["stack"], "recover_test.go")
+
+	rec = httptest.NewRecorder()
+
+	hf(rec, httptest.NewRequest(http.MethodGet, "/written", nil))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Len(t, tst.Entries(), 2)
+
+	hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+
+	ens = tst.Entries()
+
+	assert.Len(t, ens, 3)
+	assert.ErrorIs(t, ens[2].err, cse)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		hf(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+
+	assert.Len(t, tst.Entries(), 3)
+}
@@ -0,0 +1,268 @@
+package mdhttp
+
+import (
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportConfig configures a Transport
+type TransportConfig struct {
+	// Base is the round tripper that sends the requests, defaults to http.DefaultTransport
+	Base http.RoundTripper
+	// RequestIDKey is the header the context's request id is sent with, defaults to "X-Request-ID"
+	RequestIDKey string
+	// MaxBody is the max number of body bytes logged at debug, defaults to 64KiB
+	MaxBody int
+	// Levels are the levels 4xx and 5xx responses are logged at, by status class, ie 5 for 5xx
+	// defaults to warn, and classes not in the map use the default
+	Levels map[int]mdlog.Level
+	// StatusErrors makes 4xx and 5xx responses errors instead of responses
+	// problem details responses are parsed into the error's cause, see ParseProblem
+	StatusErrors bool
+}
+
+// Transport is a http.RoundTripper for calling other services
+// it sends the context's request id, see RequestIDMiddleware, and logs
+// a summary of each call with the method, url, upstream status and duration, ie
+//
+//	client := &http.Client{Transport: mdhttp.NewTransport(logger, mdhttp.TransportConfig{})}
+//
+// the request and response bodies are logged in a separate entry at debug,
+// when the response body is closed
+// the status is logged and added to errors as "upstream-status", so it isn't
+// used as the status of an error returned from a Handler, see Adapt
+type Transport struct {
+	logger mdlog.Logger
+	config TransportConfig
+}
+
+// NewTransport creates a Transport that logs with the logger
+func NewTransport(lgr mdlog.Logger, cfg TransportConfig) *Transport {
+	if cfg.Base == nil {
+		cfg.Base = http.DefaultTransport
+	}
+
+	if cfg.RequestIDKey == "" {
+		cfg.RequestIDKey = "X-Request-ID"
+	}
+
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = maxBody
+	}
+
+	lvs := map[int]mdlog.Level{
+		4: mdlog.Warn,
+		5: mdlog.Warn,
+	}
+
+	for cls, lvl := range cfg.Levels {
+		lvs[cls] = lvl
+	}
+
+	cfg.Levels = lvs
+
+	return &Transport{
+		logger: lgr,
+		config: cfg,
+	}
+}
+
+// RoundTrip sends the request
+// transport errors are wrapped in an mderr error with the method, url and duration,
+// and 4xx and 5xx responses are logged at the level for their status class,
+// and returned as errors if TransportConfig.StatusErrors is set
+// 1xx, 2xx and 3xx responses, ie the redirects http.Client follows, aren't failures
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	rid := mdctx.RequestID(ctx)
+
+	if rid != "" && req.Header.Get(t.config.RequestIDKey) == "" {
+		req = req.Clone(ctx)
+
+		req.Header.Set(t.config.RequestIDKey, rid)
+	}
+
+	md := map[string]any{
+		"method": req.Method,
+		"url":    req.URL.Redacted(),
+	}
+
+	if rid != "" {
+		md["request-id"] = rid
+	}
+
+	dbg := mdlog.Enabled(t.logger, ctx, mdlog.Debug)
+	var rqb string
+
+	if dbg {
+		rqb = t.requestBody(req)
+	}
+
+	now := time.Now()
+	res, err := t.config.Base.RoundTrip(req)
+
+	md["duration-ms"] = float64(time.Since(now).Microseconds()) / 1000
+
+	if err != nil {
+		err = mderr.Wrap(err, "http request failed", copyMetadata(md))
+
+		t.logger.Error(ctx, err, nil)
+
+		return nil, err
+	}
+
+	md["upstream-status"] = res.StatusCode
+
+	if dbg {
+		bmd := copyMetadata(md)
+
+		bmd["request-body"] = rqb
+
+		t.responseBody(res, func(bod string) {
+			bmd["response-body"] = bod
+
+			t.logger.Debug(ctx, "outgoing http request bodies", bmd)
+		})
+	}
+
+	if res.StatusCode < http.StatusBadRequest {
+		t.logger.Info(ctx, "outgoing http request", md)
+
+		return res, nil
+	}
+
+	var cse error
+
+	if t.config.StatusErrors {
+		prb, err := ParseProblem(res)
+
+		if err == nil {
+			cse = upstream(prb)
+		}
+	}
+
+	err = mderr.Wrap(cse, "http request returned an unexpected status", copyMetadata(md))
+	lvl, ok := t.config.Levels[res.StatusCode/100]
+
+	if !ok {
+		lvl = mdlog.Warn
+	}
+
+	if mdlog.Error.Allows(lvl) {
+		mdlog.Route(t.logger, ctx, lvl, err, "", nil)
+	} else {
+		mdlog.Route(t.logger, ctx, lvl, err, "", copyMetadata(md))
+	}
+
+	if !t.config.StatusErrors {
+		return res, nil
+	}
+
+	_ = res.Body.Close()
+
+	return nil, err
+}
+
+// requestBody gets up to the max bytes of the request's body
+// the body is only read if the request can make a copy of it, so it's never consumed
+func (t *Transport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return ""
+	}
+
+	bod, err := req.GetBody()
+
+	if err != nil {
+		return ""
+	}
+
+	defer bod.Close()
+
+	buf, _ := io.ReadAll(io.LimitReader(bod, int64(t.config.MaxBody)))
+
+	return string(buf)
+}
+
+// responseBody copies up to the max bytes of the response's body as the caller
+// reads it, so streaming responses aren't held up, and calls the func with
+// the copy when the body is closed
+func (t *Transport) responseBody(res *http.Response, f func(string)) {
+	if res.Body == nil || res.Body == http.NoBody {
+		f("")
+
+		return
+	}
+
+	res.Body = &teeBody{
+		ReadCloser: res.Body,
+		max:        t.config.MaxBody,
+		closed:     f,
+	}
+}
+
+// teeBody keeps a copy of up to the max bytes read from a body
+type teeBody struct {
+	io.ReadCloser
+	max    int
+	body   []byte
+	closed func(string)
+	once   sync.Once
+}
+
+func (b *teeBody) Read(buf []byte) (int, error) {
+	cnt, err := b.ReadCloser.Read(buf)
+
+	if len(b.body) < b.max {
+		cpy := buf[:cnt]
+
+		if len(cpy) > b.max-len(b.body) {
+			cpy = cpy[:b.max-len(b.body)]
+		}
+
+		b.body = append(b.body, cpy...)
+	}
+
+	return cnt, err
+}
+
+// Close closes the body and calls the func with the copy, once
+func (b *teeBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	b.once.Do(func() {
+		b.closed(string(b.body))
+	})
+
+	return err
+}
+
+// upstream rebuilds a problem error with its status as "upstream-status",
+// so another service's status isn't used as the status of our response, see Adapt
+func upstream(prb *mderr.MDErr) error {
+	md := make(map[string]any, len(prb.Metadata()))
+
+	for key, val := range prb.Metadata() {
+		md[key] = val
+	}
+
+	md["upstream-status"] = md["status"]
+
+	delete(md, "status")
+
+	return mderr.New(prb.Message(), md)
+}
+
+func copyMetadata(md map[string]any) map[string]any {
+	cpy := make(map[string]any, len(md)+2)
+
+	for key, val := range md {
+		cpy[key] = val
+	}
+
+	return cpy
+}
@@ -0,0 +1,207 @@
+package mdhttp_test
+
+import (
+	"bufio"
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdhttp"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/echo", http.StatusFound)
+
+			return
+		}
+
+		bod, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("X-Echo-ID", r.Header.Get("X-Request-ID"))
+
+		_, _ = w.Write([]byte("echo " + string(bod)))
+	}))
+
+	defer srv.Close()
+
+	tst := &testLogger{}
+	cli := &http.Client{Transport: mdhttp.NewTransport(tst, mdhttp.TransportConfig{})}
+	ctx := mdctx.WithRequestID(context.Background(), "rid")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/echo", strings.NewReader("hello"))
+
+	assert.NoError(t, err)
+
+	res, err := cli.Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "rid", res.Header.Get("X-Echo-ID"))
+	assert.Empty(t, req.Header.Get("X-Request-ID"))
+
+	bod, err := io.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "echo hello", string(bod))
+	assert.Len(t, tst.Entries(), 1)
+	assert.NoError(t, res.Body.Close())
+
+	ens := tst.Entries()
+
+	assert.Len(t, ens, 2)
+	assert.Equal(t, mdlog.Info, ens[0].level)
+	assert.Equal(t, "outgoing http request", ens[0].msg)
+	assert.Equal(t, http.MethodPost, ens[0].md["method"])
+	assert.Equal(t, srv.URL+"/echo", ens[0].md["url"])
+	assert.Equal(t, http.StatusOK, ens[0].md["upstream-status"])
+	assert.Equal(t, "rid", ens[0].md["request-id"])
+	assert.Contains(t, ens[0].md, "duration-ms")
+	assert.NotContains(t, ens[0].md, "request-body")
+	assert.Equal(t, mdlog.Debug, ens[1].level)
+	assert.Equal(t, "hello", ens[1].md["request-body"])
+	assert.Equal(t, "echo hello", ens[1].md["response-body"])
+
+	tst = &testLogger{}
+	cli = &http.Client{Transport: mdhttp.NewTransport(tst, mdhttp.TransportConfig{StatusErrors: true})}
+
+	res, err = cli.Get(srv.URL + "/redirect")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NoError(t, res.Body.Close())
+
+	for _, ent := range tst.Entries() {
+		assert.NotEqual(t, mdlog.Error, ent.level)
+		assert.NotEqual(t, mdlog.Warn, ent.level)
+	}
+}
+
+func TestTransportStream(t *testing.T) {
+	nxt := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		_, _ = w.Write([]byte("data: one\n\n"))
+
+		w.(http.Flusher).Flush()
+
+		<-nxt
+	}))
+
+	defer srv.Close()
+	defer close(nxt)
+
+	tst := &testLogger{}
+	cli := &http.Client{Transport: mdhttp.NewTransport(tst, mdhttp.TransportConfig{})}
+
+	res, err := cli.Get(srv.URL)
+
+	assert.NoError(t, err)
+
+	lin, err := bufio.NewReader(res.Body).ReadString('\n')
+
+	assert.NoError(t, err)
+	assert.Equal(t, "data: one\n", lin)
+	assert.NoError(t, res.Body.Close())
+
+	ens := tst.Entries()
+
+	assert.Len(t, ens, 2)
+	assert.Equal(t, "data: one\n\n", ens[1].md["response-body"])
+}
+
+func TestTransportErrors(t *testing.T) {
+	srv := httptest.NewServer(mdhttp.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		return mderr.New("user not found", map[string]any{
+			"status": http.StatusNotFound,
+			"code":   "user-not-found",
+		})
+	}, &testLogger{}, mdhttp.HandlerConfig{
+		Renderer: mdhttp.ProblemRenderer(mdhttp.ProblemConfig{
+			Extensions: []string{"code"},
+		}),
+	}))
+
+	tst := &testLogger{}
+
+	cli := &http.Client{Transport: mdhttp.NewTransport(tst, mdhttp.TransportConfig{
+		StatusErrors: true,
+	})}
+
+	_, err := cli.Get(srv.URL + "/users/1234")
+
+	mde, ok := mderr.AsIs(mderr.Cause(err))
+
+	assert.True(t, ok)
+	assert.Equal(t, "http request returned an unexpected status", mde.Message())
+	assert.Equal(t, http.StatusNotFound, mde.Metadata()["upstream-status"])
+	assert.Equal(t, http.MethodGet, mde.Metadata()["method"])
+	assert.Equal(t, "user not found", mderr.Message(mde.Cause()))
+	assert.Equal(t, "user-not-found", mderr.Metadata(mde.Cause())["code"])
+	assert.Equal(t, http.StatusNotFound, mderr.Metadata(mde.Cause())["upstream-status"])
+	assert.NotContains(t, mderr.Metadata(mde.Cause()), "status")
+
+	hf := mdhttp.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		return mde
+	}, &testLogger{}, mdhttp.HandlerConfig{})
+
+	rec := httptest.NewRecorder()
+
+	hf(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	srv.Close()
+
+	_, err = cli.Get(srv.URL)
+
+	mde, ok = mderr.AsIs(mderr.Cause(err))
+
+	assert.True(t, ok)
+	assert.Equal(t, "http request failed", mde.Message())
+	assert.Contains(t, mde.Metadata(), "duration-ms")
+
+	ens := tst.Entries()
+
+	assert.Len(t, ens, 3)
+	assert.Equal(t, mdlog.Debug, ens[0].level)
+	assert.Contains(t, ens[0].md["response-body"], "user-not-found")
+	assert.Equal(t, mdlog.Warn, ens[1].level)
+	assert.Equal(t, http.StatusNotFound, ens[1].md["upstream-status"])
+	assert.Equal(t, mdlog.Error, ens[2].level)
+}
+
+func TestTransportLevels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	defer srv.Close()
+
+	tst := &testLogger{}
+
+	cli := &http.Client{Transport: mdhttp.NewTransport(tst, mdhttp.TransportConfig{
+		Levels: map[int]mdlog.Level{5: mdlog.Error},
+	})}
+
+	res, err := cli.Get(srv.URL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.NoError(t, res.Body.Close())
+
+	ens := tst.Entries()
+
+	assert.Len(t, ens, 2)
+	assert.Equal(t, mdlog.Debug, ens[0].level)
+	assert.Equal(t, mdlog.Error, ens[1].level)
+	assert.Equal(t, http.StatusBadGateway, mderr.Metadata(ens[1].err)["upstream-status"])
+}
@@ -0,0 +1,179 @@
+package mdhttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// recorder is a http.ResponseWriter that records the response status and size,
+// and optionally a bounded copy of the body, while passing everything
+// through to the wrapped writer
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	max    int
+	body   []byte
+}
+
+// newRecorder wraps a writer, keeping a copy of up to max body bytes
+func newRecorder(w http.ResponseWriter, max int) *recorder {
+	return &recorder{
+		ResponseWriter: w,
+		max:            max,
+	}
+}
+
+// WriteHeader records the status and writes it
+func (r *recorder) WriteHeader(sts int) {
+	if r.status == 0 {
+		r.status = sts
+	}
+
+	r.ResponseWriter.WriteHeader(sts)
+}
+
+// Write writes the body, with a 200 status if none was written
+func (r *recorder) Write(buf []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	cnt, err := r.ResponseWriter.Write(buf)
+
+	if len(r.body) < r.max {
+		cpy := buf[:cnt]
+
+		if len(cpy) > r.max-len(r.body) {
+			cpy = cpy[:r.max-len(r.body)]
+		}
+
+		r.body = append(r.body, cpy...)
+	}
+
+	r.bytes += int64(cnt)
+
+	return cnt, err
+}
+
+// Unwrap gets the wrapped writer, for http.ResponseController
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Status gets the response status, 200 if none was written
+func (r *recorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
+
+// Written checks if the status was written, or the connection hijacked
+func (r *recorder) Written() bool {
+	return r.status != 0
+}
+
+// Bytes gets the number of body bytes written
+func (r *recorder) Bytes() int64 {
+	return r.bytes
+}
+
+// Body gets the copy of the body, up to the max bytes
+func (r *recorder) Body() []byte {
+	return r.body
+}
+
+// Writer gets the recorder as a writer with the same optional interfaces
+// as the wrapped writer, ie http.Flusher for streaming and server-sent events,
+// http.Hijacker for websockets and http.Pusher for http/2 server push
+func (r *recorder) Writer() http.ResponseWriter {
+	_, flu := r.ResponseWriter.(http.Flusher)
+	_, hij := r.ResponseWriter.(http.Hijacker)
+	_, pus := r.ResponseWriter.(http.Pusher)
+
+	switch {
+	case flu && hij && pus:
+		return struct {
+			*recorder
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{r, flusher{r}, hijacker{r}, pusher{r}}
+	case flu && hij:
+		return struct {
+			*recorder
+			http.Flusher
+			http.Hijacker
+		}{r, flusher{r}, hijacker{r}}
+	case flu && pus:
+		return struct {
+			*recorder
+			http.Flusher
+			http.Pusher
+		}{r, flusher{r}, pusher{r}}
+	case hij && pus:
+		return struct {
+			*recorder
+			http.Hijacker
+			http.Pusher
+		}{r, hijacker{r}, pusher{r}}
+	case flu:
+		return struct {
+			*recorder
+			http.Flusher
+		}{r, flusher{r}}
+	case hij:
+		return struct {
+			*recorder
+			http.Hijacker
+		}{r, hijacker{r}}
+	case pus:
+		return struct {
+			*recorder
+			http.Pusher
+		}{r, pusher{r}}
+	}
+
+	return r
+}
+
+type flusher struct {
+	*recorder
+}
+
+// Flush flushes the wrapped writer, with a 200 status if none was written
+func (f flusher) Flush() {
+	if f.status == 0 {
+		f.status = http.StatusOK
+	}
+
+	f.ResponseWriter.(http.Flusher).Flush()
+}
+
+type hijacker struct {
+	*recorder
+}
+
+// Hijack hijacks the wrapped writer's connection
+// the status is recorded as 101 if none was written, ie for websockets
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	con, brw, err := h.ResponseWriter.(http.Hijacker).Hijack()
+
+	if err == nil && h.status == 0 {
+		h.status = http.StatusSwitchingProtocols
+	}
+
+	return con, brw, err
+}
+
+type pusher struct {
+	*recorder
+}
+
+// Push pushes with the wrapped writer
+func (p pusher) Push(tgt string, opt *http.PushOptions) error {
+	return p.ResponseWriter.(http.Pusher).Push(tgt, opt)
+}
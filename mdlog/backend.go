@@ -0,0 +1,119 @@
+package mdlog
+
+import (
+	"github.com/chaseisabelle/md/mderr"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Constructor creates a Logger from a config
+type Constructor func(Config) (Logger, error)
+
+var backends = map[string]Constructor{}
+var backendsMu sync.RWMutex
+
+// Register makes a backend available to NewFromConfig by name
+// backends register themselves when imported, ie
+//
+//	import _ "github.com/chaseisabelle/md/mdlog/mdzap"
+//
+// registering the same name twice panics
+func Register(name string, con Constructor) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if con == nil {
+		panic("mdlog: nil constructor for backend " + name)
+	}
+
+	if _, ok := backends[name]; ok {
+		panic("mdlog: backend registered twice " + name)
+	}
+
+	backends[name] = con
+}
+
+// Backends gets the sorted names of the registered backends
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	nms := make([]string, 0, len(backends))
+
+	for nam := range backends {
+		nms = append(nms, nam)
+	}
+
+	sort.Strings(nms)
+
+	return nms
+}
+
+// Configured is the Logger created by NewFromConfig
+type Configured struct {
+	*Adapter
+	// Filter has the config's filter rules, Update it to change them at runtime,
+	// ie when the config is reloaded
+	Filter  *Filter
+	backend Logger
+}
+
+// Backend gets the backend's Logger, without the filter
+func (c *Configured) Backend() Logger {
+	return c.backend
+}
+
+// Close closes the backend, flushing and closing the file it writes to, if any
+func (c *Configured) Close() error {
+	cls, ok := c.backend.(io.Closer)
+
+	if !ok {
+		return nil
+	}
+
+	return cls.Close()
+}
+
+// NewFromConfig validates the config and creates a Logger
+// with the backend named in the config
+// the Logger is filtered with the config's filter rules, which can be
+// updated with the Configured's Filter even if the config has none
+func NewFromConfig(cfg Config) (*Configured, error) {
+	err := cfg.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	backendsMu.RLock()
+	con, ok := backends[cfg.Backend]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return nil, mderr.New("unknown log backend", map[string]any{
+			"backend":  cfg.Backend,
+			"backends": Backends(),
+		})
+	}
+
+	flt, err := NewFilter(cfg.Filter...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lgr, err := con(cfg)
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to create logger", map[string]any{
+			"backend": cfg.Backend,
+		})
+	}
+
+	return &Configured{
+		Adapter: Adapt(WithFilter(lgr, flt)),
+		Filter:  flt,
+		backend: lgr,
+	}, nil
+}
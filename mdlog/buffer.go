@@ -0,0 +1,198 @@
+package mdlog
+
+import (
+	"context"
+	"sync"
+)
+
+type bufferKey struct{}
+
+// Buffer holds a context's debug, info and trace entries until
+// they are flushed or discarded
+// see WithBuffering
+type Buffer struct {
+	mutex     sync.Mutex
+	max       int
+	entries   []func()
+	dropped   int
+	flushed   bool
+	discarded bool
+}
+
+// BufferContext adds a Buffer to the context that holds up to max entries,
+// the oldest entries are dropped when it's full
+// if max <= 0 then 1000 is used
+func BufferContext(ctx context.Context, max int) (context.Context, *Buffer) {
+	if max <= 0 {
+		max = 1000
+	}
+
+	buf := &Buffer{
+		max: max,
+	}
+
+	return context.WithValue(ctx, bufferKey{}, buf), buf
+}
+
+// ContextBuffer gets the Buffer from the context, nil if there isn't one
+func ContextBuffer(ctx context.Context) *Buffer {
+	if ctx == nil {
+		return nil
+	}
+
+	buf, _ := ctx.Value(bufferKey{}).(*Buffer)
+
+	return buf
+}
+
+// Flush writes the buffered entries in order
+// entries after a flush are written without being buffered
+func (b *Buffer) Flush() {
+	b.mutex.Lock()
+
+	ens := b.entries
+
+	b.entries = nil
+	b.flushed = true
+
+	b.mutex.Unlock()
+
+	for _, ent := range ens {
+		ent()
+	}
+}
+
+// Discard drops the buffered entries
+// entries after a discard are dropped too
+func (b *Buffer) Discard() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.dropped += len(b.entries)
+	b.entries = nil
+	b.discarded = true
+}
+
+// Dropped gets the number of entries dropped because the buffer was full or discarded
+func (b *Buffer) Dropped() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.dropped
+}
+
+// holding checks if the buffer still holds entries
+func (b *Buffer) holding() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return !b.flushed && !b.discarded
+}
+
+// hold buffers the entry, false if the buffer was already flushed
+func (b *Buffer) hold(ent func()) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.flushed {
+		return false
+	}
+
+	if b.discarded {
+		b.dropped++
+
+		return true
+	}
+
+	if len(b.entries) >= b.max {
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+
+	b.entries = append(b.entries, ent)
+
+	return true
+}
+
+// WithBuffering applies request buffering logger middleware
+// debug, info and trace entries with a Buffer in their context are held in
+// the Buffer instead of being written
+// fatal, panic and error entries flush the Buffer before being written
+// warn entries and entries without a Buffer are written as usual
+// flushed entries are written with their own level as the context's level
+// override, see LevelContext, so the backend writes them even if its level
+// is less verbose
+// held entries keep the caller they were logged from, see Caller
+// the levels it holds are enabled while the context's Buffer is holding, see
+// Enabled, so the entries reach the Buffer even if the backend's level is
+// less verbose
+func WithBuffering(lgr Logger) Logger {
+	em := func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+		if buf := ContextBuffer(ctx); buf != nil {
+			buf.Flush()
+		}
+
+		f(ctx, err, md)
+	}
+
+	mm := func(lvl Level) MsgMod {
+		return func(ctx context.Context, msg string, md map[string]any, f MsgFunc) {
+			buf := ContextBuffer(ctx)
+
+			if buf == nil {
+				f(ctx, msg, md)
+
+				return
+			}
+
+			pcx := LevelContext(pinCaller(ctx), lvl)
+
+			if !buf.hold(func() { f(pcx, msg, md) }) {
+				f(ctx, msg, md)
+			}
+		}
+	}
+
+	return &buffering{Modder: &Modder{
+		logger: lgr,
+		fatal:  em,
+		panic:  em,
+		error:  em,
+		warn:   NopMsgMod,
+		info:   mm(Info),
+		debug:  mm(Debug),
+		trace:  mm(Trace),
+		level: func(ctx context.Context, lvl Level, msg string, md map[string]any, f LvlFunc) {
+			buf := ContextBuffer(ctx)
+
+			switch {
+			case buf == nil, lvl.Base() == Warn:
+				f(ctx, lvl, msg, md)
+			case Error.Allows(lvl.Base()):
+				buf.Flush()
+
+				f(ctx, lvl, msg, md)
+			default:
+				pcx := LevelContext(pinCaller(ctx), lvl)
+
+				if !buf.hold(func() { f(pcx, lvl, msg, md) }) {
+					f(ctx, lvl, msg, md)
+				}
+			}
+		},
+	}}
+}
+
+// buffering is the WithBuffering Logger
+type buffering struct {
+	*Modder
+}
+
+// Enabled checks if the Logger writes or holds entries at the level
+func (b *buffering) Enabled(ctx context.Context, lvl Level) bool {
+	if buf := ContextBuffer(ctx); buf != nil && lvl.Base() != Warn && !Error.Allows(lvl.Base()) && buf.holding() {
+		return true
+	}
+
+	return b.Modder.Enabled(ctx, lvl)
+}
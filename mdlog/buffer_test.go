@@ -0,0 +1,137 @@
+package mdlog_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithBuffering(t *testing.T) {
+	var act []string
+
+	ef := func(_ context.Context, err error, _ map[string]any) {
+		act = append(act, err.Error())
+	}
+
+	mf := func(_ context.Context, msg string, _ map[string]any) {
+		act = append(act, msg)
+	}
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		ErrorFunc: ef,
+		WarnFunc:  mf,
+		InfoFunc:  mf,
+		DebugFunc: mf,
+	}
+
+	lgr = mdlog.WithBuffering(lgr)
+
+	ctx, buf := mdlog.BufferContext(context.Background(), 2)
+
+	lgr.Debug(ctx, "dropped", nil)
+	lgr.Debug(ctx, "debug", nil)
+	lgr.Warn(ctx, "warn", nil)
+	lgr.Info(ctx, "info", nil)
+	lgr.Info(context.Background(), "unbuffered", nil)
+
+	assert.Equal(t, []string{"warn", "unbuffered"}, act)
+
+	lgr.Error(ctx, mderr.New("error", nil), nil)
+	lgr.Debug(ctx, "after", nil)
+
+	assert.Equal(t, []string{"warn", "unbuffered", "debug", "info", "error", "after"}, act)
+	assert.Equal(t, 1, buf.Dropped())
+
+	act = nil
+	ctx, buf = mdlog.BufferContext(context.Background(), 0)
+
+	lgr.Debug(ctx, "debug", nil)
+	buf.Discard()
+	lgr.Info(ctx, "info", nil)
+	buf.Flush()
+
+	assert.Empty(t, act)
+	assert.Equal(t, 2, buf.Dropped())
+}
+
+func TestWithBufferingLevel(t *testing.T) {
+	var act []string
+
+	mf := func(lvl mdlog.Level) mdlog.MsgFunc {
+		return func(ctx context.Context, msg string, _ map[string]any) {
+			if mdlog.Info.AllowsContext(ctx, lvl) {
+				act = append(act, msg)
+			}
+		}
+	}
+
+	lgr := mdlog.WithBuffering(&TestLogger{
+		ErrorFunc: func(_ context.Context, err error, _ map[string]any) {
+			act = append(act, err.Error())
+		},
+		DebugFunc: mf(mdlog.Debug),
+		TraceFunc: mf(mdlog.Trace),
+	})
+
+	ctx, _ := mdlog.BufferContext(context.Background(), 0)
+
+	lgr.Debug(context.Background(), "unbuffered", nil)
+	lgr.Debug(ctx, "debug", nil)
+	lgr.(mdlog.LevelLogger).At(ctx, mdlog.Trace, "trace", nil)
+	lgr.Error(ctx, mderr.New("error", nil), nil)
+
+	assert.Equal(t, []string{"debug", "trace", "error"}, act)
+}
+
+func TestWithBufferingEnabled(t *testing.T) {
+	var act []string
+
+	mf := func(lvl mdlog.Level) mdlog.MsgFunc {
+		return func(ctx context.Context, msg string, md map[string]any) {
+			if mdlog.Info.AllowsContext(ctx, lvl) {
+				act = append(act, fmt.Sprintf("%s:%s:%v", lvl, msg, md))
+			}
+		}
+	}
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		ErrorFunc: func(_ context.Context, err error, _ map[string]any) {
+			act = append(act, "error:"+err.Error())
+		},
+		InfoFunc:  mf(mdlog.Info),
+		DebugFunc: mf(mdlog.Debug),
+		EnabledFunc: func(ctx context.Context, lvl mdlog.Level) bool {
+			return mdlog.Info.AllowsContext(ctx, lvl)
+		},
+	}
+
+	lgr = mdlog.WithBuffering(lgr)
+	lgr = mdlog.WithLimits(lgr, mdlog.LimitConfig{MaxStringLength: 3})
+	lgr = mdlog.NewPipeline().Build(lgr)
+
+	ctx, _ := mdlog.BufferContext(context.Background(), 0)
+
+	assert.True(t, mdlog.Enabled(lgr, ctx, mdlog.Debug))
+	assert.False(t, mdlog.Enabled(lgr, context.Background(), mdlog.Debug))
+
+	lgr.Debug(ctx, "debug", map[string]any{"body": "abcdef"})
+	lgr.Debug(context.Background(), "unbuffered", nil)
+
+	assert.Empty(t, act)
+
+	lgr.Error(ctx, mderr.New("boom", nil), nil)
+
+	assert.Equal(t, []string{
+		"debug:deb…(+2 bytes):map[body:abc…(+3 bytes) truncated:[message body]]",
+		"error:boom",
+	}, act)
+
+	assert.False(t, mdlog.Enabled(lgr, ctx, mdlog.Debug))
+}
@@ -0,0 +1,152 @@
+package mdlog
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+type callerKey struct{}
+
+// pkgPath is mdlog's import path
+// frames in mdlog and its sub packages (the backends and mods) are skipped
+var pkgPath = reflect.TypeOf(Modder{}).PkgPath()
+
+// maxCallers is the max stack depth searched for the caller
+const maxCallers = 64
+
+// Caller gets the file:line of the code that logged an entry, ie "mdhttp/middleware.go:42"
+// it skips the frames of mdlog, the backends and the mods, no matter how many
+// mods are stacked, including user-defined mods and Loggers between them
+// the backends call it when Config.Caller is set
+// empty string if the caller can't be found
+func Caller(ctx context.Context) string {
+	pcs, ok := contextCallers(ctx)
+
+	if !ok {
+		pcs = make([]uintptr, maxCallers)
+		pcs = pcs[:runtime.Callers(2, pcs)]
+	}
+
+	frm, ok := caller(pcs)
+
+	if !ok {
+		return ""
+	}
+
+	return filepath.Base(filepath.Dir(frm.File)) + "/" + filepath.Base(frm.File) + ":" + strconv.Itoa(frm.Line)
+}
+
+// caller finds the first frame that isn't mdlog's, or code called by a Modder
+// code called by a Modder is a user-defined mod or Logger between mods
+func caller(pcs []uintptr) (runtime.Frame, bool) {
+	frs := runtime.CallersFrames(pcs)
+	frm, mor := frs.Next()
+
+	for {
+		if frm.Function == "" {
+			return frm, false
+		}
+
+		nxt, nmr := frs.Next()
+
+		if !internal(frm.Function) && !(nxt.Function != "" && dispatch(nxt.Function)) {
+			return frm, true
+		}
+
+		if !mor {
+			return frm, false
+		}
+
+		frm, mor = nxt, nmr
+	}
+}
+
+// pinCaller captures the caller in the context
+// so entries written later, ie flushed from a Buffer, keep their caller
+func pinCaller(ctx context.Context) context.Context {
+	if ctx == nil {
+		return ctx
+	}
+
+	if _, ok := contextCallers(ctx); ok {
+		return ctx
+	}
+
+	pcs := make([]uintptr, maxCallers)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	return context.WithValue(ctx, callerKey{}, pcs)
+}
+
+// detachCaller gets a background context with only the context's pinned caller
+// for entries that are written later and aren't about the context's request
+func detachCaller(ctx context.Context) context.Context {
+	pcs, ok := contextCallers(ctx)
+
+	if !ok {
+		return context.Background()
+	}
+
+	return context.WithValue(context.Background(), callerKey{}, pcs)
+}
+
+func contextCallers(ctx context.Context) ([]uintptr, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	pcs, ok := ctx.Value(callerKey{}).([]uintptr)
+
+	return pcs, ok
+}
+
+// internal checks if the function is in mdlog or its sub packages
+// tests are not internal
+func internal(fun string) bool {
+	pkg := funcPackage(fun)
+
+	if strings.HasSuffix(pkg, "_test") {
+		return false
+	}
+
+	return pkg == pkgPath || strings.HasPrefix(pkg, pkgPath+"/")
+}
+
+// dispatch checks if the function calls mods or the Loggers under mods
+func dispatch(fun string) bool {
+	if funcPackage(fun) != pkgPath {
+		return false
+	}
+
+	nam := strings.TrimPrefix(fun, pkgPath+".")
+
+	for _, pfx := range []string{"(*Modder).", "(*pipeline).", "(*Adapter).", "LogFunc.", "chainErr.", "chainMsg.", "msgLvlMod.", "WithRouteMod.", "WithEntryMod.", "At", "Route", "Log"} {
+		if strings.HasPrefix(nam, pfx) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// funcPackage gets the package path from a function name,
+// ie "github.com/chaseisabelle/md/mdlog" from "github.com/chaseisabelle/md/mdlog.(*Modder).Info"
+func funcPackage(fun string) string {
+	idx := strings.LastIndex(fun, "/")
+
+	if idx < 0 {
+		idx = 0
+	}
+
+	dot := strings.Index(fun[idx:], ".")
+
+	if dot < 0 {
+		return fun
+	}
+
+	return fun[:idx+dot]
+}
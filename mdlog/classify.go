@@ -0,0 +1,114 @@
+package mdlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"reflect"
+)
+
+// Classification maps the errors it matches to a level
+// every condition that is set has to match, ie
+//
+//	mdlog.Classification{Is: context.Canceled, Level: mdlog.Warn}
+//	mdlog.Classification{As: new(*net.OpError), Level: mdlog.Warn}
+//	mdlog.Classification{Code: "not-found", Level: mdlog.Info}
+type Classification struct {
+	// Is matches errors where errors.Is(err, Is)
+	Is error
+	// As matches errors where errors.As(err, As)
+	// it's a pointer to the error type, like the errors.As target, ie new(*net.OpError)
+	As any
+	// Code matches errors with the code in the metadata of any error
+	// in the stack, compared as strings, ie fmt.Sprint(code) == Code
+	Code string
+	// CodeKey is the error metadata key Code is looked up with, defaults to "code"
+	CodeKey string
+	// Level is the level matching errors are written at
+	Level Level
+}
+
+// errorType is the reflect type of the error interface
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// validate panics if the classification has no conditions, or if As
+// isn't a valid errors.As target
+func (c Classification) validate() {
+	if c.Is == nil && c.As == nil && c.Code == "" {
+		panic("mdlog: classification without conditions")
+	}
+
+	if c.As == nil {
+		return
+	}
+
+	typ := reflect.TypeOf(c.As)
+
+	if typ.Kind() != reflect.Pointer || reflect.ValueOf(c.As).IsNil() {
+		panic("mdlog: classification As must be a non-nil pointer")
+	}
+
+	if elm := typ.Elem(); elm.Kind() != reflect.Interface && !elm.Implements(errorType) {
+		panic("mdlog: classification As must be a pointer to an interface or to a type implementing error")
+	}
+}
+
+// matches checks the error against all of the classification's conditions
+func (c Classification) matches(err error) bool {
+	if c.Is != nil && !errors.Is(err, c.Is) {
+		return false
+	}
+
+	if c.As != nil && !errors.As(err, reflect.New(reflect.TypeOf(c.As).Elem()).Interface()) {
+		return false
+	}
+
+	if c.Code != "" && !c.code(err) {
+		return false
+	}
+
+	return true
+}
+
+func (c Classification) code(err error) bool {
+	key := c.CodeKey
+
+	if key == "" {
+		key = "code"
+	}
+
+	for _, cur := range mderr.Array(err) {
+		val, ok := mderr.Metadata(cur)[key]
+
+		if ok && fmt.Sprint(val) == c.Code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithErrorClassifier applies error classifier logger middleware
+// the returned Logger writes error entries at the level of the first
+// classification that matches the error, and at the error level if none match
+// fatal and panic entries are never reclassified
+// it panics if a classification has no conditions, or if its As isn't
+// a valid errors.As target, like errors.As does
+func WithErrorClassifier(lgr Logger, cls ...Classification) Logger {
+	for _, cla := range cls {
+		cla.validate()
+	}
+
+	return WithErrorMod(lgr, func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+		for _, cla := range cls {
+			if cla.matches(err) {
+				Route(lgr, ctx, cla.Level, err, "", md)
+
+				return
+			}
+		}
+
+		f(ctx, err, md)
+	})
+}
@@ -0,0 +1,86 @@
+package mdlog_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestWithErrorClassifier(t *testing.T) {
+	var act []string
+
+	ef := func(lvl mdlog.Level) mdlog.ErrFunc {
+		return func(_ context.Context, err error, _ map[string]any) {
+			act = append(act, lvl.String()+":"+err.Error())
+		}
+	}
+
+	mf := func(lvl mdlog.Level) mdlog.MsgFunc {
+		return func(_ context.Context, msg string, _ map[string]any) {
+			act = append(act, lvl.String()+":"+msg)
+		}
+	}
+
+	lgr := mdlog.WithErrorClassifier(&TestLogger{
+		FatalFunc: ef(mdlog.Fatal),
+		ErrorFunc: ef(mdlog.Error),
+		WarnFunc:  mf(mdlog.Warn),
+		InfoFunc:  mf(mdlog.Info),
+		DebugFunc: mf(mdlog.Debug),
+	}, mdlog.Classification{
+		Is:    context.Canceled,
+		Level: mdlog.Warn,
+	}, mdlog.Classification{
+		As:    new(*fs.PathError),
+		Level: mdlog.Debug,
+	}, mdlog.Classification{
+		Code:  "not-found",
+		Level: mdlog.Info,
+	})
+
+	_, perr := os.Open("/does/not/exist")
+
+	lgr.Error(nil, mderr.Wrap(context.Canceled, "canceled", nil), nil)
+	lgr.Error(nil, mderr.Wrap(perr, "open", nil), nil)
+	lgr.Error(nil, mderr.Wrap(mderr.New("missing", map[string]any{"code": "not-found"}), "get", nil), nil)
+	lgr.Error(nil, mderr.New("other", nil), nil)
+	lgr.Fatal(nil, context.Canceled, nil)
+
+	assert.Equal(t, []string{
+		"warn:canceled: context canceled",
+		"debug:open: " + perr.Error(),
+		"info:get: missing",
+		"error:other",
+		"fatal:context canceled",
+	}, act)
+}
+
+func TestWithErrorClassifierInvalid(t *testing.T) {
+	lgr := &TestLogger{}
+
+	assert.Panics(t, func() {
+		mdlog.WithErrorClassifier(lgr, mdlog.Classification{Level: mdlog.Warn})
+	})
+
+	assert.Panics(t, func() {
+		mdlog.WithErrorClassifier(lgr, mdlog.Classification{As: new(testError), Level: mdlog.Warn})
+	})
+
+	assert.Panics(t, func() {
+		mdlog.WithErrorClassifier(lgr, mdlog.Classification{As: fs.PathError{}, Level: mdlog.Warn})
+	})
+
+	assert.NotPanics(t, func() {
+		mdlog.WithErrorClassifier(lgr, mdlog.Classification{As: new(interface{ Timeout() bool }), Level: mdlog.Warn})
+	})
+}
+
+type testError struct{}
+
+func (*testError) Error() string {
+	return "test"
+}
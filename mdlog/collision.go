@@ -0,0 +1,160 @@
+package mdlog
+
+import (
+	"strconv"
+)
+
+// debugKey is the metadata key the debug collisions are added with
+const debugKey = "metadata-collisions"
+
+// Collision is what happens when a mod adds a metadata key the entry already has
+type Collision int
+
+const (
+	// ModWins replaces the entry's value with the mod's, this is the default
+	ModWins Collision = iota
+	// CallWins keeps the entry's value and drops the mod's
+	CallWins
+	// Rename adds the mod's value with a numbered suffix, ie "request-id-2"
+	Rename
+	// Namespace adds the mod's value to a map under the namespace key, ie md["md"]["request-id"]
+	Namespace
+)
+
+// CollisionConfig is how a mod handles metadata key collisions
+// pass it to the mods, ie WithRequestID(lgr, "", cfg), or to Pipeline.Collisions
+// so each Logger has its own, the zero value is ModWins
+type CollisionConfig struct {
+	// Policy is what happens on a collision, defaults to ModWins
+	Policy Collision
+	// Namespace is the key the mods' values are nested under with the Namespace policy,
+	// defaults to "md"
+	// if the entry has a non-map value with the key, the Rename policy is used
+	Namespace string
+	// Debug adds the colliding keys to the entry's metadata under "metadata-collisions"
+	// if the entry has its own "metadata-collisions" that isn't a []string, the keys
+	// are added per the policy
+	Debug bool
+}
+
+// collisions gets the last of a mod's optional collision configs
+func collisions(cls []CollisionConfig) CollisionConfig {
+	if len(cls) == 0 {
+		return CollisionConfig{}
+	}
+
+	return cls[len(cls)-1]
+}
+
+// AddMetadata adds a mod's metadata to an entry's metadata
+// the mod's value replaces the entry's on a collision, see CollisionConfig.Add
+func AddMetadata(md map[string]any, key string, val any) map[string]any {
+	return CollisionConfig{}.Add(md, key, val)
+}
+
+// Add adds a mod's metadata to an entry's metadata, handling collisions
+// with the entry's keys per the config, ie a call's "request-id" metadata
+// colliding with WithRequestID's
+// the entry's metadata map is changed, or created if it's nil, and returned
+// use it in custom mods to handle collisions the same way as the built-in mods
+func (c CollisionConfig) Add(md map[string]any, key string, val any) map[string]any {
+	if md == nil {
+		md = map[string]any{}
+	}
+
+	if _, ok := md[key]; !ok {
+		md[key] = val
+
+		return md
+	}
+
+	if c.Debug {
+		c.debug(md, key)
+	}
+
+	nam := c.namespace()
+
+	switch c.Policy {
+	case CallWins:
+	case Rename:
+		rename(md, key, val)
+	case Namespace:
+		nsm, ok := md[nam].(map[string]any)
+
+		if _, set := md[nam]; set && !ok {
+			rename(md, key, val)
+
+			break
+		}
+
+		cpy := make(map[string]any, len(nsm)+1)
+
+		for nsk, nsv := range nsm {
+			cpy[nsk] = nsv
+		}
+
+		cpy[key] = val
+		md[nam] = cpy
+	default:
+		md[key] = val
+	}
+
+	return md
+}
+
+// rename adds the value with the first free numbered suffix
+func rename(md map[string]any, key string, val any) {
+	for i := 2; ; i++ {
+		nam := key + "-" + strconv.Itoa(i)
+
+		if _, ok := md[nam]; !ok {
+			md[nam] = val
+
+			return
+		}
+	}
+}
+
+// namespace gets the namespace key
+func (c CollisionConfig) namespace() string {
+	if c.Namespace == "" {
+		return "md"
+	}
+
+	return c.Namespace
+}
+
+// debug adds the colliding key to the debug collisions
+// the entry's own "metadata-collisions" is handled per the policy
+func (c CollisionConfig) debug(md map[string]any, key string) {
+	cls, ok := md[debugKey].([]string)
+
+	if _, set := md[debugKey]; ok || !set {
+		md[debugKey] = append(cls, key)
+
+		return
+	}
+
+	c.Debug = false
+
+	switch c.Policy {
+	case Rename:
+		for i := 2; ; i++ {
+			nam := debugKey + "-" + strconv.Itoa(i)
+			val, set := md[nam]
+
+			if cls, ok := val.([]string); ok || !set {
+				md[nam] = append(cls, key)
+
+				return
+			}
+		}
+	case Namespace:
+		nsm, _ := md[c.namespace()].(map[string]any)
+		cls, _ := nsm[debugKey].([]string)
+
+		c.Add(md, debugKey, append(cls, key))
+	default:
+		c.Add(md, debugKey, []string{key})
+	}
+}
@@ -0,0 +1,133 @@
+package mdlog_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollisions(t *testing.T) {
+	tests := map[string]struct {
+		config mdlog.CollisionConfig
+		exp    map[string]any
+	}{
+		"mod wins": {
+			config: mdlog.CollisionConfig{},
+			exp:    map[string]any{"env": "prod", "request-id": "rid"},
+		},
+		"call wins": {
+			config: mdlog.CollisionConfig{Policy: mdlog.CallWins},
+			exp:    map[string]any{"env": "call", "request-id": "call"},
+		},
+		"rename": {
+			config: mdlog.CollisionConfig{Policy: mdlog.Rename},
+			exp:    map[string]any{"env": "call", "env-2": "prod", "request-id": "call", "request-id-2": "rid"},
+		},
+		"namespace": {
+			config: mdlog.CollisionConfig{Policy: mdlog.Namespace, Namespace: "mods"},
+			exp:    map[string]any{"env": "call", "request-id": "call", "mods": map[string]any{"env": "prod", "request-id": "rid"}},
+		},
+		"debug": {
+			config: mdlog.CollisionConfig{Policy: mdlog.CallWins, Debug: true},
+			exp:    map[string]any{"env": "call", "request-id": "call", "metadata-collisions": []string{"request-id", "env"}},
+		},
+	}
+
+	for nam, tst := range tests {
+		t.Run(nam, func(t *testing.T) {
+			var act map[string]any
+			var lgr mdlog.Logger
+
+			bck := &TestLogger{
+				InfoFunc: func(_ context.Context, _ string, md map[string]any) {
+					act = md
+				},
+			}
+
+			lgr = mdlog.WithPersistedMetadata(bck, map[string]any{"env": "prod"}, tst.config)
+			lgr = mdlog.WithRequestID(lgr, "", tst.config)
+
+			ctx := mdctx.WithRequestID(context.Background(), "rid")
+
+			lgr.Info(ctx, "info", map[string]any{"env": "call", "request-id": "call"})
+
+			assert.Equal(t, tst.exp, act)
+
+			pip := mdlog.NewPipeline().
+				RequestID("").
+				PersistedMetadata(map[string]any{"env": "prod"}).
+				Collisions(tst.config).
+				Build(bck)
+
+			pip.Info(ctx, "info", map[string]any{"env": "call", "request-id": "call"})
+
+			exp := map[string]any{}
+
+			for key, val := range tst.exp {
+				exp[key] = val
+			}
+
+			assert.ElementsMatch(t, exp["metadata-collisions"], act["metadata-collisions"])
+
+			delete(exp, "metadata-collisions")
+			delete(act, "metadata-collisions")
+
+			assert.Equal(t, exp, act)
+		})
+	}
+}
+
+func TestCollisionsPerLogger(t *testing.T) {
+	var act []map[string]any
+
+	lgr := &TestLogger{
+		InfoFunc: func(_ context.Context, _ string, md map[string]any) {
+			act = append(act, md)
+		},
+	}
+
+	ctx := mdctx.WithRequestID(context.Background(), "rid")
+
+	mdlog.WithRequestID(lgr, "", mdlog.CollisionConfig{Policy: mdlog.CallWins}).Info(ctx, "info", map[string]any{"request-id": "call"})
+	mdlog.WithRequestID(lgr, "").Info(ctx, "info", map[string]any{"request-id": "call"})
+
+	assert.Equal(t, []map[string]any{{"request-id": "call"}, {"request-id": "rid"}}, act)
+}
+
+func TestAddMetadata(t *testing.T) {
+	cfg := mdlog.CollisionConfig{Policy: mdlog.Namespace}
+
+	assert.Equal(t, map[string]any{"foo": "bar"}, cfg.Add(nil, "foo", "bar"))
+	assert.Equal(t, map[string]any{"md": "call", "foo": "call", "foo-2": "mod"}, cfg.Add(map[string]any{"md": "call", "foo": "call"}, "foo", "mod"))
+	assert.Equal(t, map[string]any{"foo": "call", "md": map[string]any{"foo": "mod"}}, cfg.Add(map[string]any{"foo": "call"}, "foo", "mod"))
+
+	cfg = mdlog.CollisionConfig{Policy: mdlog.Rename}
+
+	assert.Equal(t, map[string]any{"foo": 1, "foo-2": 2, "foo-3": 3}, cfg.Add(map[string]any{"foo": 1, "foo-2": 2}, "foo", 3))
+	assert.Equal(t, map[string]any{"foo": "mod"}, mdlog.AddMetadata(map[string]any{"foo": "call"}, "foo", "mod"))
+
+	cfg = mdlog.CollisionConfig{Policy: mdlog.Rename, Debug: true}
+	cmd := map[string]any{"foo": 1, "bar": 1, "metadata-collisions": "call"}
+
+	cfg.Add(cmd, "foo", 2)
+	cfg.Add(cmd, "bar", 2)
+
+	assert.Equal(t, map[string]any{"foo": 1, "foo-2": 2, "bar": 1, "bar-2": 2, "metadata-collisions": "call", "metadata-collisions-2": []string{"foo", "bar"}}, cmd)
+
+	cfg = mdlog.CollisionConfig{Policy: mdlog.Namespace, Debug: true}
+	cmd = map[string]any{"foo": 1, "bar": 1, "metadata-collisions": "call"}
+
+	cfg.Add(cmd, "foo", 2)
+	cfg.Add(cmd, "bar", 2)
+
+	assert.Equal(t, map[string]any{"foo": 1, "bar": 1, "metadata-collisions": "call", "md": map[string]any{"foo": 2, "bar": 2, "metadata-collisions": []string{"foo", "bar"}}}, cmd)
+
+	cfg = mdlog.CollisionConfig{Policy: mdlog.CallWins, Debug: true}
+	cmd = map[string]any{"foo": 1, "metadata-collisions": "call"}
+
+	cfg.Add(cmd, "foo", 2)
+
+	assert.Equal(t, map[string]any{"foo": 1, "metadata-collisions": "call"}, cmd)
+}
@@ -1,5 +1,260 @@
 package mdlog
 
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config is the logger config
+// it can be decoded from json, or yaml with mdyaml, ie
+//
+//	backend: zap
+//	level: debug
+//	file:
+//	  path: /var/log/app.log
+//	  max-size: 104857600
+//	  max-backups: 10
+//	  compress: true
+//	filter:
+//	  - action: drop
+//	    message: health check
 type Config struct {
-	Level Level
+	// Backend is the name of the registered backend NewFromConfig uses, ie "zap" or "zero"
+	Backend string `json:"backend" yaml:"backend"`
+	// Level is the most verbose level that gets written
+	Level Level `json:"level" yaml:"level"`
+	// File is a rotating file the backends write to instead of stdout+stderr,
+	// if it has a path
+	File mdfile.Config `json:"file" yaml:"file"`
+	// Caller adds the file:line of the code that logged each entry
+	// as the "caller" field, see Caller
+	Caller bool `json:"caller" yaml:"caller"`
+	// Filter are the rules NewFromConfig filters entries with, see WithFilter
+	Filter []Rule `json:"filter" yaml:"filter"`
+}
+
+// DefaultConfig gets the config used for any fields
+// not set by ConfigFromEnv, ConfigFromJSON or mdyaml.ConfigFromYAML
+func DefaultConfig() Config {
+	return Config{
+		Backend: "zero",
+		Level:   Info,
+	}
+}
+
+// ConfigFromEnv loads the config from env vars
+// the vars are named <prefix>_<FIELD>, ie MYAPP_LOG_LEVEL for a "MYAPP_LOG" prefix
+// if prefix == "" then the vars are just <FIELD>, ie LEVEL
+// the filter rules are a json array in <prefix>_FILTER
+// unset vars use the DefaultConfig values
+func ConfigFromEnv(prefix string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, "_") + "_"
+	}
+
+	if val, ok := os.LookupEnv(prefix + "BACKEND"); ok {
+		cfg.Backend = val
+	}
+
+	if val, ok := os.LookupEnv(prefix + "LEVEL"); ok {
+		lvl, err := ParseLevel(val)
+
+		if err != nil {
+			return cfg, mderr.Wrap(err, "failed to parse log level env var", map[string]any{
+				"key": prefix + "LEVEL",
+			})
+		}
+
+		cfg.Level = lvl
+	}
+
+	if val, ok := os.LookupEnv(prefix + "CALLER"); ok {
+		clr, err := strconv.ParseBool(val)
+
+		if err != nil {
+			return cfg, mderr.Wrap(err, "failed to parse log caller env var", map[string]any{
+				"key": prefix + "CALLER",
+			})
+		}
+
+		cfg.Caller = clr
+	}
+
+	if val, ok := os.LookupEnv(prefix + "FILTER"); ok {
+		err := json.Unmarshal([]byte(val), &cfg.Filter)
+
+		if err != nil {
+			return cfg, mderr.Wrap(err, "failed to parse log filter env var", map[string]any{
+				"key": prefix + "FILTER",
+			})
+		}
+	}
+
+	err := fileConfigFromEnv(&cfg.File, prefix+"FILE_")
+
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// ConfigFromJSON decodes the config from json
+// unset fields use the DefaultConfig values
+func ConfigFromJSON(buf []byte) (Config, error) {
+	cfg := DefaultConfig()
+	dec := json.NewDecoder(bytes.NewReader(buf))
+
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(&cfg)
+
+	if err != nil {
+		return cfg, mderr.Wrap(err, "failed to decode json log config", nil)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate checks every field of the config
+func (c Config) Validate() error {
+	if c.Backend == "" {
+		return mderr.New("missing log backend", nil)
+	}
+
+	if !c.Level.Valid() {
+		return mderr.New("invalid log level", map[string]any{
+			"level": int(c.Level),
+		})
+	}
+
+	if c.File.Path != "" {
+		err := c.File.Validate()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, rul := range c.Filter {
+		err := rul.Validate()
+
+		if err != nil {
+			return mderr.Wrap(err, "invalid filter rule", map[string]any{
+				"index": i,
+			})
+		}
+	}
+
+	return nil
+}
+
+// Outputs opens the writers the backends write entries to
+// stdout and stderr, or the rotating file for both if the config has a file path
+// the closer closes the file, and does nothing for stdout and stderr
+func (c Config) Outputs() (io.Writer, io.Writer, io.Closer, error) {
+	if c.File.Path == "" {
+		return os.Stdout, os.Stderr, nopCloser{}, nil
+	}
+
+	fil, err := mdfile.New(c.File)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return fil, fil, fil, nil
+}
+
+func fileConfigFromEnv(cfg *mdfile.Config, prefix string) error {
+	if val, ok := os.LookupEnv(prefix + "PATH"); ok {
+		cfg.Path = val
+	}
+
+	ints := map[string]func(int64){
+		"MAX_SIZE": func(i int64) {
+			cfg.MaxSize = i
+		},
+		"MAX_BACKUPS": func(i int64) {
+			cfg.MaxBackups = int(i)
+		},
+	}
+
+	for key, set := range ints {
+		val, ok := os.LookupEnv(prefix + key)
+
+		if !ok {
+			continue
+		}
+
+		i, err := strconv.ParseInt(val, 10, 64)
+
+		if err != nil {
+			return mderr.Wrap(err, "failed to parse log file env var", map[string]any{
+				"key": prefix + key,
+			})
+		}
+
+		set(i)
+	}
+
+	durs := map[string]*mdfile.Duration{
+		"INTERVAL": &cfg.Interval,
+		"MAX_AGE":  &cfg.MaxAge,
+	}
+
+	for key, dur := range durs {
+		val, ok := os.LookupEnv(prefix + key)
+
+		if !ok {
+			continue
+		}
+
+		err := dur.UnmarshalText([]byte(val))
+
+		if err != nil {
+			return mderr.Wrap(err, "failed to parse log file env var", map[string]any{
+				"key": prefix + key,
+			})
+		}
+	}
+
+	bols := map[string]*bool{
+		"COMPRESS":         &cfg.Compress,
+		"REOPEN_ON_SIGHUP": &cfg.ReopenOnSIGHUP,
+	}
+
+	for key, bol := range bols {
+		val, ok := os.LookupEnv(prefix + key)
+
+		if !ok {
+			continue
+		}
+
+		b, err := strconv.ParseBool(val)
+
+		if err != nil {
+			return mderr.Wrap(err, "failed to parse log file env var", map[string]any{
+				"key": prefix + key,
+			})
+		}
+
+		*bol = b
+	}
+
+	return nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
 }
@@ -0,0 +1,189 @@
+package mdlog_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	for lvl, nam := range mdlog.Levels() {
+		act, err := mdlog.ParseLevel(nam)
+
+		assert.NoError(t, err)
+		assert.Equal(t, lvl, act)
+	}
+
+	act, err := mdlog.ParseLevel(" DEBUG ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdlog.Debug, act)
+
+	_, err = mdlog.ParseLevel("loud")
+
+	assert.Error(t, err)
+}
+
+func TestLevelText(t *testing.T) {
+	buf, err := json.Marshal(map[string]mdlog.Level{"level": mdlog.Warn})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"level":"warn"}`, string(buf))
+
+	var lvl mdlog.Level
+
+	assert.NoError(t, lvl.UnmarshalText([]byte("error")))
+	assert.Equal(t, mdlog.Error, lvl)
+	assert.Error(t, lvl.UnmarshalText([]byte("loud")))
+
+	_, err = mdlog.Level(1234).MarshalText()
+
+	assert.Error(t, err)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("MYAPP_LOG_BACKEND", "zap")
+	t.Setenv("MYAPP_LOG_LEVEL", "debug")
+	t.Setenv("MYAPP_LOG_CALLER", "true")
+
+	cfg, err := mdlog.ConfigFromEnv("MYAPP_LOG")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "zap", cfg.Backend)
+	assert.Equal(t, mdlog.Debug, cfg.Level)
+	assert.True(t, cfg.Caller)
+
+	cfg, err = mdlog.ConfigFromEnv("UNSET")
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdlog.DefaultConfig(), cfg)
+
+	t.Setenv("MYAPP_LOG_LEVEL", "loud")
+
+	_, err = mdlog.ConfigFromEnv("MYAPP_LOG")
+
+	assert.Error(t, err)
+}
+
+func TestConfigFromJSON(t *testing.T) {
+	cfg, err := mdlog.ConfigFromJSON([]byte(`{"backend":"zap","level":"warn"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdlog.Config{Backend: "zap", Level: mdlog.Warn}, cfg)
+
+	cfg, err = mdlog.ConfigFromJSON([]byte(`{}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdlog.DefaultConfig(), cfg)
+
+	_, err = mdlog.ConfigFromJSON([]byte(`{"level":"loud"}`))
+
+	assert.Error(t, err)
+
+	_, err = mdlog.ConfigFromJSON([]byte(`{"lvl":"debug"}`))
+
+	assert.Error(t, err)
+
+	_, err = mdlog.ConfigFromJSON([]byte(`{"backend":""}`))
+
+	assert.Error(t, err)
+}
+
+type ClosingLogger struct {
+	TestLogger
+	closed bool
+}
+
+func (c *ClosingLogger) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+func TestNewFromConfig(t *testing.T) {
+	msgs := make([]string, 0)
+	bck := &ClosingLogger{
+		TestLogger: TestLogger{
+			InfoFunc: func(_ context.Context, msg string, _ map[string]any) {
+				msgs = append(msgs, msg)
+			},
+		},
+	}
+
+	mdlog.Register("test", func(cfg mdlog.Config) (mdlog.Logger, error) {
+		return bck, nil
+	})
+
+	lgr, err := mdlog.NewFromConfig(mdlog.Config{Backend: "test", Level: mdlog.Info, Filter: []mdlog.Rule{{Message: "noisy"}}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, bck, lgr.Backend())
+	assert.Contains(t, mdlog.Backends(), "test")
+
+	lgr.Info(context.Background(), "noisy", nil)
+	lgr.Info(context.Background(), "quiet", nil)
+
+	assert.NoError(t, lgr.Filter.Update(mdlog.Rule{Message: "quiet"}))
+
+	lgr.Info(context.Background(), "noisy", nil)
+	lgr.Info(context.Background(), "quiet", nil)
+
+	assert.Equal(t, []string{"quiet", "noisy"}, msgs)
+	assert.NoError(t, lgr.Close())
+	assert.True(t, bck.closed)
+
+	lgr, err = mdlog.NewFromConfig(mdlog.Config{Backend: "test", Level: mdlog.Info})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, lgr.Filter)
+
+	_, err = mdlog.NewFromConfig(mdlog.Config{Backend: "nope", Level: mdlog.Info})
+
+	assert.Error(t, err)
+
+	_, err = mdlog.NewFromConfig(mdlog.Config{Backend: "test", Level: mdlog.Level(1234)})
+
+	assert.Error(t, err)
+}
+
+func TestFileConfig(t *testing.T) {
+	t.Setenv("APP_FILE_PATH", "/var/log/app.log")
+	t.Setenv("APP_FILE_MAX_SIZE", "1024")
+	t.Setenv("APP_FILE_MAX_AGE", "24h")
+	t.Setenv("APP_FILE_COMPRESS", "true")
+
+	cfg, err := mdlog.ConfigFromEnv("APP")
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdfile.Config{
+		Path:     "/var/log/app.log",
+		MaxSize:  1024,
+		MaxAge:   mdfile.Duration(24 * time.Hour),
+		Compress: true,
+	}, cfg.File)
+
+	t.Setenv("APP_FILE_MAX_AGE", "forever")
+
+	_, err = mdlog.ConfigFromEnv("APP")
+
+	assert.Error(t, err)
+
+	cfg, err = mdlog.ConfigFromJSON([]byte(`{"file":{"path":"app.log","interval":"1h","max-backups":3}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdfile.Duration(time.Hour), cfg.File.Interval)
+	assert.Equal(t, 3, cfg.File.MaxBackups)
+
+	cfg, err = mdlog.ConfigFromJSON([]byte(`{"file":{"path":"app.log","max-age":"30m"}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdfile.Duration(30*time.Minute), cfg.File.MaxAge)
+
+	_, err = mdlog.ConfigFromJSON([]byte(`{"file":{"path":"app.log","max-size":-1}}`))
+
+	assert.Error(t, err)
+}
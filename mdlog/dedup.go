@@ -0,0 +1,235 @@
+package mdlog
+
+import (
+	"context"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupConfig is the WithDedup config
+type DedupConfig struct {
+	// Window is how long repeats of an error are suppressed for, defaults to 1m
+	Window time.Duration
+	// Keys are the metadata keys that are part of an error's fingerprint,
+	// along with the error stack's messages
+	Keys []string
+	// Key is the summary entry's metadata key, defaults to "dedup"
+	Key string
+	// Collisions is how the key is added if the entry already has it
+	Collisions CollisionConfig
+}
+
+type dedup struct {
+	ctx   context.Context
+	err   error
+	md    map[string]any
+	f     ErrFunc
+	count int
+	first time.Time
+	last  time.Time
+	timer *time.Timer
+}
+
+// Dedup is the Logger made by WithDedup
+// Close it on shutdown to write the summaries of windows that are still open
+type Dedup struct {
+	*Modder
+	config DedupConfig
+	mutex  sync.Mutex
+	dedups map[string]*dedup
+	closed bool
+}
+
+// WithDedup applies error deduplication logger middleware
+// the first error entry with a fingerprint is written, and repeats within
+// the window are suppressed
+// when the window closes, if there were repeats, a summary entry is written
+// with the first error and its metadata, plus the count and first+last seen
+// times under the key
+// the summary isn't about any one request, so it's written without the
+// first entry's context, only its caller
+// fatal and panic entries are never suppressed
+func WithDedup(lgr Logger, cfg DedupConfig) *Dedup {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+
+	if cfg.Key == "" {
+		cfg.Key = "dedup"
+	}
+
+	ddp := &Dedup{
+		config: cfg,
+		dedups: map[string]*dedup{},
+	}
+
+	ddp.Modder = &Modder{
+		logger: lgr,
+		fatal:  NopErrMod,
+		panic:  NopErrMod,
+		error:  ddp.mod,
+		warn:   NopMsgMod,
+		info:   NopMsgMod,
+		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
+	}
+
+	return ddp
+}
+
+// Flush writes the summaries of the open windows now, and closes them
+func (d *Dedup) Flush() {
+	d.mutex.Lock()
+
+	dds := make([]*dedup, 0, len(d.dedups))
+
+	for fpt, ddp := range d.dedups {
+		ddp.timer.Stop()
+
+		dds = append(dds, ddp)
+
+		delete(d.dedups, fpt)
+	}
+
+	d.mutex.Unlock()
+
+	sort.Slice(dds, func(i, j int) bool {
+		return dds[i].first.Before(dds[j].first)
+	})
+
+	for _, ddp := range dds {
+		d.summarize(ddp)
+	}
+}
+
+// Close writes the summaries of the open windows
+// errors logged after it are written without deduplication
+func (d *Dedup) Close() error {
+	d.mutex.Lock()
+	d.closed = true
+	d.mutex.Unlock()
+
+	d.Flush()
+
+	return nil
+}
+
+func (d *Dedup) mod(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+	if err == nil {
+		f(ctx, err, md)
+
+		return
+	}
+
+	fpt := fingerprint(err, md, d.config.Keys)
+	now := time.Now()
+
+	d.mutex.Lock()
+
+	if d.closed {
+		d.mutex.Unlock()
+
+		f(ctx, err, md)
+
+		return
+	}
+
+	ddp, ok := d.dedups[fpt]
+
+	if ok {
+		ddp.count++
+		ddp.last = now
+
+		d.mutex.Unlock()
+
+		return
+	}
+
+	smd := make(map[string]any, len(md))
+
+	for key, val := range md {
+		smd[key] = val
+	}
+
+	d.dedups[fpt] = &dedup{
+		ctx:   detachCaller(pinCaller(ctx)),
+		err:   err,
+		md:    smd,
+		f:     f,
+		count: 1,
+		first: now,
+		last:  now,
+		timer: time.AfterFunc(d.config.Window, func() {
+			d.expire(fpt)
+		}),
+	}
+
+	d.mutex.Unlock()
+
+	f(ctx, err, md)
+}
+
+// expire closes a fingerprint's window
+func (d *Dedup) expire(fpt string) {
+	d.mutex.Lock()
+
+	ddp := d.dedups[fpt]
+
+	delete(d.dedups, fpt)
+
+	d.mutex.Unlock()
+
+	if ddp != nil {
+		d.summarize(ddp)
+	}
+}
+
+// summarize writes the summary entry, if there were repeats
+func (d *Dedup) summarize(ddp *dedup) {
+	if ddp.count < 2 {
+		return
+	}
+
+	smd := make(map[string]any, len(ddp.md)+1)
+
+	for key, val := range ddp.md {
+		smd[key] = val
+	}
+
+	smd = d.config.Collisions.Add(smd, d.config.Key, map[string]any{
+		"count":      ddp.count,
+		"suppressed": ddp.count - 1,
+		"first-seen": ddp.first.Format(time.RFC3339Nano),
+		"last-seen":  ddp.last.Format(time.RFC3339Nano),
+	})
+
+	ddp.f(ddp.ctx, ddp.err, smd)
+}
+
+// fingerprint identifies an error by its stack's messages
+// and the values of the metadata keys
+func fingerprint(err error, md map[string]any, kys []string) string {
+	buf := strings.Builder{}
+
+	for _, cur := range mderr.Array(err) {
+		buf.WriteString(mderr.Message(cur))
+		buf.WriteByte(0)
+	}
+
+	for _, key := range kys {
+		val, ok := md[key]
+
+		if ok {
+			buf.WriteString(fmt.Sprintf("%s=%v", key, val))
+		}
+
+		buf.WriteByte(0)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,139 @@
+package mdlog_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithDedup(t *testing.T) {
+	var mtx sync.Mutex
+	var mds []map[string]any
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		ErrorFunc: func(_ context.Context, _ error, md map[string]any) {
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			mds = append(mds, md)
+		},
+	}
+
+	lgr = mdlog.WithDedup(lgr, mdlog.DedupConfig{
+		Window: 50 * time.Millisecond,
+		Keys:   []string{"host"},
+	})
+
+	ctx := context.Background()
+	err := func() error {
+		return mderr.Wrap(fmt.Errorf("connection refused"), "failed to query", nil)
+	}
+
+	for i := 0; i < 5; i++ {
+		lgr.Error(ctx, err(), map[string]any{
+			"host": "db1",
+			"try":  i,
+		})
+	}
+
+	lgr.Error(ctx, err(), map[string]any{
+		"host": "db2",
+	})
+
+	lgr.Error(ctx, mderr.New("other error", nil), nil)
+
+	mtx.Lock()
+	assert.Len(t, mds, 3)
+	assert.Equal(t, 0, mds[0]["try"])
+	mtx.Unlock()
+
+	assert.Eventually(t, func() bool {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		return len(mds) == 4
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	assert.Len(t, mds, 4)
+
+	smd := mds[3]
+	ddp := smd["dedup"].(map[string]any)
+
+	assert.Equal(t, "db1", smd["host"])
+	assert.Equal(t, 0, smd["try"])
+	assert.Equal(t, 5, ddp["count"])
+	assert.Equal(t, 4, ddp["suppressed"])
+	assert.NotEmpty(t, ddp["first-seen"])
+	assert.NotEmpty(t, ddp["last-seen"])
+}
+
+func TestDedupClose(t *testing.T) {
+	var ctxs []context.Context
+	var mds []map[string]any
+
+	ddp := mdlog.WithDedup(&TestLogger{
+		ErrorFunc: func(ctx context.Context, _ error, md map[string]any) {
+			ctxs = append(ctxs, ctx)
+			mds = append(mds, md)
+		},
+	}, mdlog.DedupConfig{
+		Window: time.Hour,
+	})
+
+	ctx := mdctx.WithRequestID(context.Background(), "rid")
+
+	for i := 0; i < 3; i++ {
+		ddp.Error(ctx, mderr.New("error", nil), nil)
+	}
+
+	ddp.Error(ctx, mderr.New("once", nil), nil)
+
+	assert.Len(t, mds, 2)
+	assert.NoError(t, ddp.Close())
+	assert.Len(t, mds, 3)
+	assert.Equal(t, "rid", mdctx.RequestID(ctxs[0]))
+	assert.Empty(t, mdctx.RequestID(ctxs[2]))
+	assert.Equal(t, 3, mds[2]["dedup"].(map[string]any)["count"])
+
+	ddp.Error(ctx, mderr.New("error", nil), nil)
+	ddp.Error(ctx, mderr.New("error", nil), nil)
+
+	assert.Len(t, mds, 5)
+}
+
+func TestDedupCollisions(t *testing.T) {
+	var act map[string]any
+
+	ddp := mdlog.WithDedup(&TestLogger{
+		ErrorFunc: func(_ context.Context, _ error, md map[string]any) {
+			act = md
+		},
+	}, mdlog.DedupConfig{
+		Window:     time.Minute,
+		Collisions: mdlog.CollisionConfig{Policy: mdlog.Rename},
+	})
+
+	defer ddp.Close()
+
+	for i := 0; i < 2; i++ {
+		ddp.Error(context.Background(), mderr.New("error", nil), map[string]any{"dedup": "call"})
+	}
+
+	ddp.Flush()
+
+	assert.Equal(t, "call", act["dedup"])
+	assert.Equal(t, 2, act["dedup-2"].(map[string]any)["count"])
+}
@@ -0,0 +1,264 @@
+package mdlog
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Entry is a log entry
+// fatal, panic and error entries have an Error, and the rest have a Message
+type Entry struct {
+	// Context is the context the entry was logged with
+	Context context.Context
+	// Level is the entry's level
+	Level Level
+	// Time is when the entry was logged, backends use the current time if it's zero
+	Time time.Time
+	// Message is the message of a non-error entry
+	Message string
+	// Error is the error of a fatal, panic or error entry
+	Error error
+	// Metadata is the entry's metadata
+	Metadata map[string]any
+	// Caller is the file:line of the code that logged the entry,
+	// backends with Config.Caller set find it if it's empty, see Caller
+	Caller string
+	// Name is the name of the logger, see WithName
+	Name string
+}
+
+// NewEntry creates an entry logged now
+func NewEntry(ctx context.Context, lvl Level, err error, msg string, md map[string]any) Entry {
+	return Entry{
+		Context:  ctx,
+		Level:    lvl,
+		Time:     time.Now(),
+		Message:  msg,
+		Error:    err,
+		Metadata: md,
+	}
+}
+
+// EntryLogger is a Logger that writes whole entries
+// the extra fields, like the time and name, are lost when an entry is written
+// to a Logger that isn't an EntryLogger, see Log
+type EntryLogger interface {
+	Log(Entry)
+}
+
+// Log writes an entry to a Logger
+// EntryLoggers get the whole entry, and other Loggers get
+// the entry through the method for its level, see Route
+func Log(lgr Logger, ent Entry) {
+	enl, ok := lgr.(EntryLogger)
+
+	if ok {
+		enl.Log(ent)
+
+		return
+	}
+
+	Route(lgr, ent.Context, ent.Level, ent.Error, ent.Message, ent.Metadata)
+}
+
+// LogFunc is a func that writes entries
+// it's a full Logger so a Logger only has to implement one func, ie
+//
+//	lgr := mdlog.LogFunc(func(ent mdlog.Entry) {
+//		fmt.Println(ent.Level, ent.Message, ent.Error)
+//	})
+//
+// like the backends, it exits after writing fatal entries and panics after
+// writing panic entries, so the func doesn't have to
+type LogFunc func(Entry)
+
+// Log calls the func
+// it exits after fatal entries and panics after panic entries
+func (f LogFunc) Log(ent Entry) {
+	f(ent)
+
+	switch ent.Level.Base() {
+	case Fatal:
+		os.Exit(1)
+	case Panic:
+		if ent.Error != nil {
+			panic(ent.Error)
+		}
+
+		panic(ent.Message)
+	}
+}
+
+// Fatal writes a fatal entry then exits
+func (f LogFunc) Fatal(ctx context.Context, err error, md map[string]any) {
+	f.Log(NewEntry(ctx, Fatal, err, "", md))
+}
+
+// Panic writes a panic entry then panics
+func (f LogFunc) Panic(ctx context.Context, err error, md map[string]any) {
+	f.Log(NewEntry(ctx, Panic, err, "", md))
+}
+
+// Error writes an error entry
+func (f LogFunc) Error(ctx context.Context, err error, md map[string]any) {
+	f(NewEntry(ctx, Error, err, "", md))
+}
+
+// Warn writes a warn entry
+func (f LogFunc) Warn(ctx context.Context, msg string, md map[string]any) {
+	f(NewEntry(ctx, Warn, nil, msg, md))
+}
+
+// Info writes an info entry
+func (f LogFunc) Info(ctx context.Context, msg string, md map[string]any) {
+	f(NewEntry(ctx, Info, nil, msg, md))
+}
+
+// Debug writes a debug entry
+func (f LogFunc) Debug(ctx context.Context, msg string, md map[string]any) {
+	f(NewEntry(ctx, Debug, nil, msg, md))
+}
+
+// Trace writes a trace entry
+func (f LogFunc) Trace(ctx context.Context, msg string, md map[string]any) {
+	f(NewEntry(ctx, Trace, nil, msg, md))
+}
+
+// At writes an entry at any level
+func (f LogFunc) At(ctx context.Context, lvl Level, msg string, md map[string]any) {
+	f.Log(NewEntry(ctx, lvl, nil, msg, md))
+}
+
+// Adapter is a five-method Logger adapted to the rest of the Logger contract, see Adapt
+type Adapter struct {
+	logger Logger
+}
+
+// Adapt adapts a five-method Logger to the rest of the Logger contract
+// panic entries are written as errors then panic, trace entries are written as debug,
+// user-defined levels are written at their base level, and whole entries
+// are written with the method for their level, see Log
+// Loggers that already have the methods keep using their own
+func Adapt(lgr Logger) *Adapter {
+	adp, ok := lgr.(*Adapter)
+
+	if ok {
+		return adp
+	}
+
+	return &Adapter{
+		logger: lgr,
+	}
+}
+
+// Fatal writes a fatal entry then exits
+func (a *Adapter) Fatal(ctx context.Context, err error, md map[string]any) {
+	a.logger.Fatal(ctx, err, md)
+}
+
+// Panic writes a panic entry then panics
+func (a *Adapter) Panic(ctx context.Context, err error, md map[string]any) {
+	LogPanic(a.logger, ctx, err, md)
+}
+
+// Error writes an error entry
+func (a *Adapter) Error(ctx context.Context, err error, md map[string]any) {
+	a.logger.Error(ctx, err, md)
+}
+
+// Warn writes a warn entry
+func (a *Adapter) Warn(ctx context.Context, msg string, md map[string]any) {
+	a.logger.Warn(ctx, msg, md)
+}
+
+// Info writes an info entry
+func (a *Adapter) Info(ctx context.Context, msg string, md map[string]any) {
+	a.logger.Info(ctx, msg, md)
+}
+
+// Debug writes a debug entry
+func (a *Adapter) Debug(ctx context.Context, msg string, md map[string]any) {
+	a.logger.Debug(ctx, msg, md)
+}
+
+// Trace writes a trace entry
+func (a *Adapter) Trace(ctx context.Context, msg string, md map[string]any) {
+	LogTrace(a.logger, ctx, msg, md)
+}
+
+// At writes an entry at any level
+func (a *Adapter) At(ctx context.Context, lvl Level, msg string, md map[string]any) {
+	At(a.logger, ctx, lvl, msg, md)
+}
+
+// Log writes a whole entry
+func (a *Adapter) Log(ent Entry) {
+	Log(a.logger, ent)
+}
+
+// Enabled checks if the adapted Logger writes entries at the level
+func (a *Adapter) Enabled(ctx context.Context, lvl Level) bool {
+	return Enabled(a.logger, ctx, lvl)
+}
+
+// EntryMod is a func to modify a whole entry
+// the mod can change any of the entry's fields, including its level,
+// and writes it with the func, or doesn't write it at all
+type EntryMod func(Entry, func(Entry))
+
+// WithEntryMod adds whole entry middleware
+// entries logged with the Logger methods are made into entries logged now,
+// and entries written with Log keep all their fields
+func WithEntryMod(l Logger, f EntryMod) Logger {
+	nxt := func(ent Entry) {
+		Log(l, ent)
+	}
+
+	em := func(lvl Level) ErrMod {
+		return func(ctx context.Context, err error, md map[string]any, _ ErrFunc) {
+			f(NewEntry(ctx, lvl, err, "", md), nxt)
+		}
+	}
+
+	mm := func(lvl Level) MsgMod {
+		return func(ctx context.Context, msg string, md map[string]any, _ MsgFunc) {
+			f(NewEntry(ctx, lvl, nil, msg, md), nxt)
+		}
+	}
+
+	return &Modder{
+		logger: l,
+		fatal:  em(Fatal),
+		panic:  em(Panic),
+		error:  em(Error),
+		warn:   mm(Warn),
+		info:   mm(Info),
+		debug:  mm(Debug),
+		trace:  mm(Trace),
+		level: func(ctx context.Context, lvl Level, msg string, md map[string]any, _ LvlFunc) {
+			f(NewEntry(ctx, lvl, nil, msg, md), nxt)
+		},
+		entry: f,
+	}
+}
+
+// WithName applies logger name middleware
+// the returned Logger sets the name of its entries,
+// which the backends write as the "logger" field
+// nested names are joined with a dot, ie "api.users"
+func WithName(lgr Logger, name string) Logger {
+	if name == "" {
+		return lgr
+	}
+
+	return WithEntryMod(lgr, func(ent Entry, f func(Entry)) {
+		if ent.Name == "" {
+			ent.Name = name
+		} else {
+			ent.Name = name + "." + ent.Name
+		}
+
+		f(ent)
+	})
+}
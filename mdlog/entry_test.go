@@ -0,0 +1,126 @@
+package mdlog_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLogFunc(t *testing.T) {
+	var act []mdlog.Entry
+
+	var lgr mdlog.Logger = mdlog.LogFunc(func(ent mdlog.Entry) {
+		act = append(act, ent)
+	})
+
+	err := mderr.New("error", nil)
+
+	lgr.Error(nil, err, nil)
+	lgr.Info(nil, "info", map[string]any{"foo": "bar"})
+	mdlog.At(lgr, nil, mdlog.Level(35), "custom", nil)
+
+	assert.Len(t, act, 3)
+	assert.Equal(t, mdlog.Error, act[0].Level)
+	assert.Equal(t, err, act[0].Error)
+	assert.Equal(t, mdlog.Info, act[1].Level)
+	assert.Equal(t, "info", act[1].Message)
+	assert.Equal(t, map[string]any{"foo": "bar"}, act[1].Metadata)
+	assert.False(t, act[1].Time.IsZero())
+	assert.Equal(t, mdlog.Level(35), act[2].Level)
+}
+
+func TestLog(t *testing.T) {
+	var act []string
+
+	lgr := &TestLogger{
+		ErrorFunc: func(_ context.Context, err error, _ map[string]any) {
+			act = append(act, "error:"+err.Error())
+		},
+		InfoFunc: func(_ context.Context, msg string, _ map[string]any) {
+			act = append(act, "info:"+msg)
+		},
+	}
+
+	mdlog.Log(lgr, mdlog.Entry{Level: mdlog.Info, Message: "info"})
+	mdlog.Log(lgr, mdlog.Entry{Level: mdlog.Error, Message: "error"})
+
+	assert.Equal(t, []string{"info:info", "error:error"}, act)
+}
+
+func TestModderLog(t *testing.T) {
+	var act []mdlog.Entry
+
+	var lgr mdlog.Logger = mdlog.LogFunc(func(ent mdlog.Entry) {
+		act = append(act, ent)
+	})
+
+	lgr = mdlog.WithRequestID(lgr, "")
+	lgr = mdlog.WithName(lgr, "api")
+	lgr = mdlog.WithErrorTrace(lgr, "")
+	lgr = mdlog.WithName(lgr, "users")
+	lgr = mdlog.WithEntryMod(lgr, func(ent mdlog.Entry, f func(mdlog.Entry)) {
+		if ent.Metadata["warn"] == true {
+			ent.Level = mdlog.Warn
+		}
+
+		f(ent)
+	})
+
+	tim := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := mdctx.WithRequestID(context.Background(), "rid")
+
+	mdlog.Log(lgr, mdlog.Entry{Context: ctx, Level: mdlog.Info, Time: tim, Message: "info", Metadata: map[string]any{"warn": true}})
+	lgr.Error(ctx, mderr.New("error", nil), nil)
+
+	assert.Len(t, act, 2)
+	assert.Equal(t, mdlog.Warn, act[0].Level)
+	assert.Equal(t, tim, act[0].Time)
+	assert.Equal(t, "api.users", act[0].Name)
+	assert.Equal(t, "rid", act[0].Metadata["request-id"])
+	assert.Equal(t, mdlog.Error, act[1].Level)
+	assert.Equal(t, "api.users", act[1].Name)
+	assert.Contains(t, act[1].Metadata, "error-trace")
+}
+
+func TestLogFuncPanic(t *testing.T) {
+	var act []mdlog.Entry
+
+	lgr := mdlog.LogFunc(func(ent mdlog.Entry) {
+		act = append(act, ent)
+	})
+
+	err := mderr.New("panic", nil)
+
+	assert.PanicsWithValue(t, err, func() {
+		lgr.Panic(nil, err, nil)
+	})
+
+	assert.PanicsWithError(t, "at panic", func() {
+		mdlog.At(lgr, nil, mdlog.Panic, "at panic", nil)
+	})
+
+	assert.Len(t, act, 2)
+	assert.Equal(t, mdlog.Panic, act[0].Level)
+}
+
+func TestAdapt(t *testing.T) {
+	lgy := &LegacyLogger{}
+	adp := mdlog.Adapt(lgy)
+
+	assert.Same(t, adp, mdlog.Adapt(adp))
+
+	adp.Trace(nil, "trace", nil)
+	adp.At(nil, mdlog.Warn, "at", nil)
+	adp.Log(mdlog.Entry{Level: mdlog.Info, Message: "entry"})
+
+	assert.PanicsWithError(t, "panic", func() {
+		adp.Panic(nil, mderr.New("panic", nil), nil)
+	})
+
+	assert.True(t, adp.Enabled(nil, mdlog.Trace))
+	assert.Equal(t, []string{"debug:trace", "warn:at", "info:entry", "error:panic"}, lgy.Entries)
+}
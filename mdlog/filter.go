@@ -0,0 +1,253 @@
+package mdlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Action is what a filter Rule does with the entries it matches
+type Action string
+
+const (
+	Drop Action = "drop"
+	Keep Action = "keep"
+)
+
+// Rule matches entries for a Filter
+// every condition that is set has to match, so a rule with no conditions
+// matches every entry, ie in the yaml config
+//
+//	filter:
+//	  - action: drop
+//	    levels: [info]
+//	    message-prefix: health check
+//	    metadata:
+//	      path: /healthz
+type Rule struct {
+	// Action is what happens to matching entries, defaults to Drop
+	Action Action `json:"action" yaml:"action"`
+	// Levels matches entries at any of the levels, all levels if empty
+	Levels []Level `json:"levels" yaml:"levels"`
+	// Message matches the entry's message exactly
+	// an error entry's message is the error's message, see mderr.Message
+	Message string `json:"message" yaml:"message"`
+	// MessagePrefix matches the start of the entry's message
+	MessagePrefix string `json:"message-prefix" yaml:"message-prefix"`
+	// MessageRegex matches the entry's message with a regular expression
+	MessageRegex string `json:"message-regex" yaml:"message-regex"`
+	// Keys matches entries that have all the metadata keys
+	Keys []string `json:"keys" yaml:"keys"`
+	// Metadata matches entries that have all the metadata values,
+	// compared as strings, ie fmt.Sprint(md[key]) == val
+	Metadata map[string]string `json:"metadata" yaml:"metadata"`
+	// ErrorRoot matches error entries where the root error's message is this
+	ErrorRoot string `json:"error-root" yaml:"error-root"`
+	// ErrorIs matches error entries where errors.Is(err, ErrorIs)
+	// it can't be loaded from config
+	ErrorIs error `json:"-" yaml:"-"`
+}
+
+// Validate checks the rule's action and regex
+func (r Rule) Validate() error {
+	_, err := r.compile()
+
+	return err
+}
+
+// rule is a validated Rule
+type rule struct {
+	Rule
+	regex *regexp.Regexp
+}
+
+func (r Rule) compile() (*rule, error) {
+	switch r.Action {
+	case "":
+		r.Action = Drop
+	case Drop, Keep:
+	default:
+		return nil, mderr.New("invalid filter rule action", map[string]any{
+			"action": string(r.Action),
+		})
+	}
+
+	for _, lvl := range r.Levels {
+		if !lvl.Valid() {
+			return nil, mderr.New("invalid filter rule level", map[string]any{
+				"level": int(lvl),
+			})
+		}
+	}
+
+	cmp := &rule{
+		Rule: r,
+	}
+
+	if r.MessageRegex != "" {
+		rgx, err := regexp.Compile(r.MessageRegex)
+
+		if err != nil {
+			return nil, mderr.Wrap(err, "invalid filter rule message regex", map[string]any{
+				"regex": r.MessageRegex,
+			})
+		}
+
+		cmp.regex = rgx
+	}
+
+	return cmp, nil
+}
+
+// matches checks the entry against all of the rule's conditions
+func (r *rule) matches(lvl Level, err error, msg string, md map[string]any) bool {
+	if len(r.Levels) > 0 && !r.level(lvl) {
+		return false
+	}
+
+	if err != nil {
+		msg = mderr.Message(err)
+	}
+
+	if r.Message != "" && msg != r.Message {
+		return false
+	}
+
+	if r.MessagePrefix != "" && !strings.HasPrefix(msg, r.MessagePrefix) {
+		return false
+	}
+
+	if r.regex != nil && !r.regex.MatchString(msg) {
+		return false
+	}
+
+	for _, key := range r.Keys {
+		if _, ok := md[key]; !ok {
+			return false
+		}
+	}
+
+	for key, val := range r.Metadata {
+		mdv, ok := md[key]
+
+		if !ok || fmt.Sprint(mdv) != val {
+			return false
+		}
+	}
+
+	if r.ErrorRoot != "" && (err == nil || mderr.Message(mderr.Root(err)) != r.ErrorRoot) {
+		return false
+	}
+
+	if r.ErrorIs != nil && !errors.Is(err, r.ErrorIs) {
+		return false
+	}
+
+	return true
+}
+
+func (r *rule) level(lvl Level) bool {
+	for _, rlv := range r.Levels {
+		if rlv == lvl {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Filter decides which entries get written
+// the first rule that matches an entry decides if it's kept or dropped,
+// and entries that no rule matches are kept
+// so a Keep rule before a broader Drop rule is an exception to it
+type Filter struct {
+	mutex sync.RWMutex
+	rules []*rule
+}
+
+// NewFilter creates a Filter with the rules
+func NewFilter(rules ...Rule) (*Filter, error) {
+	flt := &Filter{}
+
+	return flt, flt.Update(rules...)
+}
+
+// Update replaces the filter's rules, ie when the config is reloaded
+// if any rule is invalid the rules are not changed
+func (f *Filter) Update(rules ...Rule) error {
+	cmps := make([]*rule, 0, len(rules))
+
+	for i, rul := range rules {
+		cmp, err := rul.compile()
+
+		if err != nil {
+			return mderr.Wrap(err, "invalid filter rule", map[string]any{
+				"index": i,
+			})
+		}
+
+		cmps = append(cmps, cmp)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.rules = cmps
+
+	return nil
+}
+
+// Keep checks if an entry gets written
+func (f *Filter) Keep(lvl Level, err error, msg string, md map[string]any) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	for _, rul := range f.rules {
+		if rul.matches(lvl, err, msg, md) {
+			return rul.Action == Keep
+		}
+	}
+
+	return true
+}
+
+// WithFilter applies filter logger middleware
+// the returned Logger drops the entries the filter doesn't keep
+// fatal and panic entries are always written, so they still exit and panic
+func WithFilter(lgr Logger, flt *Filter) Logger {
+	em := func(lvl Level) ErrMod {
+		return func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+			if flt.Keep(lvl, err, "", md) {
+				f(ctx, err, md)
+			}
+		}
+	}
+
+	mm := func(lvl Level) MsgMod {
+		return func(ctx context.Context, msg string, md map[string]any, f MsgFunc) {
+			if flt.Keep(lvl, nil, msg, md) {
+				f(ctx, msg, md)
+			}
+		}
+	}
+
+	return &Modder{
+		logger: lgr,
+		fatal:  NopErrMod,
+		panic:  NopErrMod,
+		error:  em(Error),
+		warn:   mm(Warn),
+		info:   mm(Info),
+		debug:  mm(Debug),
+		trace:  mm(Trace),
+		level: func(ctx context.Context, lvl Level, msg string, md map[string]any, f LvlFunc) {
+			if Panic.Allows(lvl.Base()) || flt.Keep(lvl, nil, msg, md) {
+				f(ctx, lvl, msg, md)
+			}
+		},
+	}
+}
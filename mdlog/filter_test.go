@@ -0,0 +1,137 @@
+package mdlog_test
+
+import (
+	"context"
+	"errors"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	errNoisy := errors.New("noisy")
+
+	flt, err := mdlog.NewFilter(
+		mdlog.Rule{
+			Action:  mdlog.Keep,
+			Levels:  []mdlog.Level{mdlog.Info},
+			Message: "health check",
+			Keys:    []string{"failed"},
+		},
+		mdlog.Rule{
+			Levels:        []mdlog.Level{mdlog.Info},
+			MessagePrefix: "health",
+		},
+		mdlog.Rule{
+			MessageRegex: `^cache (hit|miss)$`,
+		},
+		mdlog.Rule{
+			Metadata: map[string]string{"path": "/metrics", "code": "200"},
+		},
+		mdlog.Rule{
+			ErrorRoot: "connection reset",
+		},
+		mdlog.Rule{
+			ErrorIs: errNoisy,
+		},
+	)
+
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		level mdlog.Level
+		err   error
+		msg   string
+		md    map[string]any
+		keep  bool
+	}{
+		"no match":         {mdlog.Info, nil, "hello", nil, true},
+		"prefix":           {mdlog.Info, nil, "health check", nil, false},
+		"prefix level":     {mdlog.Warn, nil, "health check", nil, true},
+		"keep exception":   {mdlog.Info, nil, "health check", map[string]any{"failed": true}, true},
+		"regex":            {mdlog.Debug, nil, "cache miss", nil, false},
+		"regex no match":   {mdlog.Debug, nil, "cache missed", nil, true},
+		"metadata":         {mdlog.Info, nil, "req", map[string]any{"path": "/metrics", "code": 200}, false},
+		"metadata partial": {mdlog.Info, nil, "req", map[string]any{"path": "/metrics", "code": 500}, true},
+		"error root":       {mdlog.Error, mderr.Wrap(mderr.New("connection reset", nil), "failed", nil), "", nil, false},
+		"error is":         {mdlog.Error, mderr.Wrap(errNoisy, "failed", nil), "", nil, false},
+		"error":            {mdlog.Error, mderr.New("failed", nil), "", nil, true},
+	}
+
+	for nam, tst := range tests {
+		t.Run(nam, func(t *testing.T) {
+			assert.Equal(t, tst.keep, flt.Keep(tst.level, tst.err, tst.msg, tst.md))
+		})
+	}
+
+	assert.Error(t, flt.Update(mdlog.Rule{MessageRegex: "("}))
+	assert.Error(t, flt.Update(mdlog.Rule{Action: "mute"}))
+	assert.False(t, flt.Keep(mdlog.Info, nil, "health", nil))
+	assert.NoError(t, flt.Update())
+	assert.True(t, flt.Keep(mdlog.Info, nil, "health", nil))
+}
+
+func TestWithFilter(t *testing.T) {
+	var act []string
+
+	ef := func(_ context.Context, err error, _ map[string]any) {
+		act = append(act, err.Error())
+	}
+
+	mf := func(_ context.Context, msg string, _ map[string]any) {
+		act = append(act, msg)
+	}
+
+	flt, err := mdlog.NewFilter(mdlog.Rule{
+		MessagePrefix: "drop",
+	})
+
+	assert.NoError(t, err)
+
+	lgr := mdlog.WithFilter(&TestLogger{
+		FatalFunc: ef,
+		ErrorFunc: ef,
+		InfoFunc:  mf,
+		AtFunc: func(_ context.Context, _ mdlog.Level, msg string, _ map[string]any) {
+			act = append(act, msg)
+		},
+	}, flt)
+
+	ctx := context.Background()
+
+	lgr.Info(ctx, "drop me", nil)
+	lgr.Info(ctx, "keep me", nil)
+	lgr.Error(ctx, mderr.New("drop error", nil), nil)
+	lgr.Fatal(ctx, mderr.New("drop fatal", nil), nil)
+	mdlog.At(lgr, ctx, mdlog.Level(35), "drop custom", nil)
+
+	assert.Equal(t, []string{"keep me", "drop fatal"}, act)
+}
+
+func TestFilterConfig(t *testing.T) {
+	cfg, err := mdlog.ConfigFromJSON([]byte(`{
+		"backend": "zero",
+		"filter": [
+			{"action": "keep", "levels": ["error"], "error-root": "timeout"},
+			{"levels": ["info", "error"], "message-regex": "^(health|timeout)", "metadata": {"path": "/healthz"}}
+		]
+	}`))
+
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Filter, 2)
+	assert.Equal(t, mdlog.Keep, cfg.Filter[0].Action)
+	assert.Equal(t, []mdlog.Level{mdlog.Info, mdlog.Error}, cfg.Filter[1].Levels)
+	assert.Equal(t, map[string]string{"path": "/healthz"}, cfg.Filter[1].Metadata)
+
+	t.Setenv("APP_FILTER", `[{"message": "noisy", "levels": ["debug"]}]`)
+
+	cfg, err = mdlog.ConfigFromEnv("APP")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []mdlog.Rule{{Message: "noisy", Levels: []mdlog.Level{mdlog.Debug}}}, cfg.Filter)
+
+	_, err = mdlog.ConfigFromJSON([]byte(`{"filter": [{"message-regex": "("}]}`))
+
+	assert.Error(t, err)
+}
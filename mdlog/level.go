@@ -1,5 +1,16 @@
 package mdlog
 
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"strings"
+	"sync"
+)
+
+// Level is the severity of an entry
+// the built-in levels keep the values they've always had, and entries are
+// ordered by their severity, not their value, see Severity
 type Level int
 
 const (
@@ -8,20 +19,241 @@ const (
 	Warn  Level = iota
 	Info  Level = iota
 	Debug Level = iota
+	Trace Level = iota
+	Panic Level = iota
 )
 
 var levels = map[Level]string{
 	Fatal: "fatal",
+	Panic: "panic",
 	Error: "error",
 	Warn:  "warn",
 	Info:  "info",
 	Debug: "debug",
+	Trace: "trace",
+}
+
+// severities are the built-in levels' severities
+// they're spaced out to leave room for user-defined levels
+var severities = map[Level]int{
+	Fatal: 0,
+	Panic: 10,
+	Error: 20,
+	Warn:  30,
+	Info:  40,
+	Debug: 50,
+	Trace: 60,
 }
 
+// bases maps user-defined levels to the built-in level
+// the backends write them as
+var bases = map[Level]Level{}
+var levelsMu sync.RWMutex
+
+// Levels gets all the known levels, including user-defined levels
 func Levels() map[Level]string {
-	return levels
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	lvs := make(map[Level]string, len(levels))
+
+	for lvl, nam := range levels {
+		lvs[lvl] = nam
+	}
+
+	return lvs
+}
+
+// RegisterLevel adds a user-defined level, ie
+//
+//	Notice := mdlog.Level(35) //<< between Warn and Info
+//	err := mdlog.RegisterLevel("notice", Notice, mdlog.Info)
+//
+// the level's value is its severity, see Severity, and it's filtered by it
+// like the built-in levels, and backends write it as the built-in base level with the user-defined name
+// write entries at user-defined levels with At
+func RegisterLevel(name string, lvl Level, base Level) error {
+	nam := strings.ToLower(strings.TrimSpace(name))
+	emd := map[string]any{
+		"name":  name,
+		"level": int(lvl),
+		"base":  int(base),
+	}
+
+	if nam == "" {
+		return mderr.New("missing log level name", emd)
+	}
+
+	if lvl < 0 {
+		return mderr.New("log level is more severe than fatal", emd)
+	}
+
+	if !base.Builtin() {
+		return mderr.New("log level base is not a built-in level", emd)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if _, ok := levels[lvl]; ok {
+		return mderr.New("log level already registered", emd)
+	}
+
+	for _, lvn := range levels {
+		if lvn == nam {
+			return mderr.New("log level name already registered", emd)
+		}
+	}
+
+	levels[lvl] = nam
+	bases[lvl] = base
+
+	return nil
+}
+
+// ParseLevel gets the level for a level name, ie "debug"
+// names are case-insensitive
+func ParseLevel(str string) (Level, error) {
+	nam := strings.ToLower(strings.TrimSpace(str))
+
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	for lvl, lvn := range levels {
+		if lvn == nam {
+			return lvl, nil
+		}
+	}
+
+	return 0, mderr.New("invalid log level", map[string]any{
+		"level": str,
+	})
 }
 
 func (l Level) String() string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
 	return levels[l]
 }
+
+// Valid checks if the level is a known level
+func (l Level) Valid() bool {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	_, ok := levels[l]
+
+	return ok
+}
+
+// Builtin checks if the level is one of the built-in levels
+func (l Level) Builtin() bool {
+	switch l {
+	case Fatal, Panic, Error, Warn, Info, Debug, Trace:
+		return true
+	default:
+		return false
+	}
+}
+
+// Base gets the built-in level the level is written as
+// built-in and unknown levels are their own base
+func (l Level) Base() Level {
+	if l.Builtin() {
+		return l
+	}
+
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	bas, ok := bases[l]
+
+	if !ok {
+		return l
+	}
+
+	return bas
+}
+
+// Severity gets the level's place in the severity order, lower is more severe
+// the built-in levels are fatal 0, panic 10, error 20, warn 30, info 40,
+// debug 50 and trace 60, and user-defined levels' severity is their value
+func (l Level) Severity() int {
+	sev, ok := severities[l]
+
+	if !ok {
+		return int(l)
+	}
+
+	return sev
+}
+
+// Allows checks if an entry at the given level gets written
+// when this is the configured level
+func (l Level) Allows(lvl Level) bool {
+	return lvl.Severity() <= l.Severity()
+}
+
+// AllowsContext checks if an entry at the given level gets written
+// when this is the configured level, honoring the context's level override
+// the override can only make the level more verbose, see LevelContext
+func (l Level) AllowsContext(ctx context.Context, lvl Level) bool {
+	ovr, ok := ContextLevel(ctx)
+
+	if ok && ovr.Severity() > l.Severity() {
+		return ovr.Allows(lvl)
+	}
+
+	return l.Allows(lvl)
+}
+
+// LevelContext sets a level override in the context
+// entries logged with the returned context are written at the override
+// level if it is more verbose than the configured level
+// invalid levels are not set and the original context is returned
+func LevelContext(ctx context.Context, lvl Level) context.Context {
+	if !lvl.Valid() {
+		return ctx
+	}
+
+	return mdctx.WithLevel(ctx, lvl.String())
+}
+
+// ContextLevel gets the level override from a context
+// false if there is no override or it's not a known level
+func ContextLevel(ctx context.Context) (Level, bool) {
+	nam := mdctx.Level(ctx)
+
+	if nam == "" {
+		return 0, false
+	}
+
+	lvl, err := ParseLevel(nam)
+
+	return lvl, err == nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.Valid() {
+		return nil, mderr.New("invalid log level", map[string]any{
+			"level": int(l),
+		})
+	}
+
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(txt []byte) error {
+	lvl, err := ParseLevel(string(txt))
+
+	if err != nil {
+		return err
+	}
+
+	*l = lvl
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package mdlog_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLevelOrder(t *testing.T) {
+	assert.True(t, mdlog.Info.Allows(mdlog.Fatal))
+	assert.True(t, mdlog.Info.Allows(mdlog.Panic))
+	assert.True(t, mdlog.Info.Allows(mdlog.Info))
+	assert.False(t, mdlog.Info.Allows(mdlog.Debug))
+	assert.False(t, mdlog.Debug.Allows(mdlog.Trace))
+	assert.True(t, mdlog.Trace.Allows(mdlog.Trace))
+}
+
+func TestLevelValues(t *testing.T) {
+	assert.Equal(t, []mdlog.Level{0, 1, 2, 3, 4}, []mdlog.Level{mdlog.Fatal, mdlog.Error, mdlog.Warn, mdlog.Info, mdlog.Debug})
+	assert.Equal(t, 10, mdlog.Panic.Severity())
+	assert.Equal(t, 60, mdlog.Trace.Severity())
+	assert.Equal(t, 35, mdlog.Level(35).Severity())
+}
+
+func TestRegisterLevel(t *testing.T) {
+	audit := mdlog.Level(27)
+
+	assert.NoError(t, mdlog.RegisterLevel("audit", audit, mdlog.Warn))
+	assert.Error(t, mdlog.RegisterLevel("audit", mdlog.Level(28), mdlog.Warn))
+	assert.Error(t, mdlog.RegisterLevel("other", audit, mdlog.Warn))
+	assert.Error(t, mdlog.RegisterLevel("info", mdlog.Level(29), mdlog.Warn))
+	assert.Error(t, mdlog.RegisterLevel("bad-base", mdlog.Level(29), audit))
+	assert.Error(t, mdlog.RegisterLevel("", mdlog.Level(29), mdlog.Warn))
+	assert.Error(t, mdlog.RegisterLevel("negative", mdlog.Level(-1), mdlog.Warn))
+
+	lvl, err := mdlog.ParseLevel("AUDIT")
+
+	assert.NoError(t, err)
+	assert.Equal(t, audit, lvl)
+	assert.Equal(t, "audit", audit.String())
+	assert.Equal(t, mdlog.Warn, audit.Base())
+	assert.Equal(t, mdlog.Info, mdlog.Info.Base())
+	assert.False(t, audit.Builtin())
+	assert.True(t, audit.Valid())
+	assert.True(t, mdlog.Warn.Allows(audit))
+	assert.False(t, mdlog.Error.Allows(audit))
+	assert.Equal(t, "audit", mdlog.Levels()[audit])
+}
+
+func TestAt(t *testing.T) {
+	notice := mdlog.Level(33)
+
+	assert.NoError(t, mdlog.RegisterLevel("notice", notice, mdlog.Info))
+
+	var act []string
+
+	ef := func(_ context.Context, err error, _ map[string]any) {
+		act = append(act, err.Error())
+	}
+
+	mf := func(_ context.Context, msg string, _ map[string]any) {
+		act = append(act, msg)
+	}
+
+	lgr := &TestLogger{
+		FatalFunc: ef,
+		PanicFunc: ef,
+		ErrorFunc: ef,
+		WarnFunc:  mf,
+		InfoFunc:  mf,
+		DebugFunc: mf,
+		TraceFunc: mf,
+		AtFunc: func(_ context.Context, lvl mdlog.Level, msg string, _ map[string]any) {
+			act = append(act, lvl.String()+":"+msg)
+		},
+	}
+
+	mod := mdlog.WithMsgMod(lgr, func(ctx context.Context, msg string, md map[string]any, f mdlog.MsgFunc) {
+		f(ctx, "modded "+msg, md)
+	})
+
+	mdlog.At(mod, nil, mdlog.Error, "error", nil)
+	mdlog.At(mod, nil, mdlog.Trace, "trace", nil)
+	mdlog.At(mod, nil, notice, "notice", nil)
+
+	assert.Equal(t, []string{"error", "modded trace", "notice:modded notice"}, act)
+
+	act = nil
+
+	mdlog.At(struct{ mdlog.Logger }{lgr}, nil, notice, "notice", nil)
+
+	assert.Equal(t, []string{"notice"}, act)
+}
+
+func TestAllowsContext(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, mdlog.Info.AllowsContext(ctx, mdlog.Debug))
+
+	dbg := mdlog.LevelContext(ctx, mdlog.Debug)
+
+	lvl, ok := mdlog.ContextLevel(dbg)
+
+	assert.True(t, ok)
+	assert.Equal(t, mdlog.Debug, lvl)
+	assert.True(t, mdlog.Info.AllowsContext(dbg, mdlog.Debug))
+	assert.False(t, mdlog.Info.AllowsContext(dbg, mdlog.Trace))
+
+	err := mdlog.LevelContext(ctx, mdlog.Error)
+
+	assert.True(t, mdlog.Info.AllowsContext(err, mdlog.Info))
+	assert.Equal(t, ctx, mdlog.LevelContext(ctx, mdlog.Level(-1)))
+}
@@ -0,0 +1,409 @@
+package mdlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mderr"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// LimitConfig is the WithLimits config
+// zero values are no limit
+type LimitConfig struct {
+	// MaxEntrySize is the max size in bytes of the message and metadata, encoded as json
+	// the biggest metadata values are cut first
+	MaxEntrySize int
+	// MaxStringLength is the max length in bytes of the message and metadata strings,
+	// including the metadata of the error's mderr chain
+	MaxStringLength int
+	// MaxDepth is the max depth of nested metadata maps and slices,
+	// the entry's metadata, and each error's in the chain, is depth 1
+	MaxDepth int
+	// MaxElements is the max number of items in metadata maps and slices,
+	// including the metadata of the error's mderr chain
+	MaxElements int
+	// Key is the metadata key the paths of the cut values are added with, defaults to "truncated"
+	Key string
+	// Collisions is how the key is added if the entry already has it
+	Collisions CollisionConfig
+}
+
+// WithLimits applies size limit logger middleware
+// the returned Logger cuts the message and metadata of entries over the limits,
+// replacing what was cut with markers like "…(+12345 bytes)", and adds
+// the paths of the cut values, ie "body" or "headers.Accept[2]", under the key
+// the error's mderr chain is cut the same way, its paths are like "error[1].query",
+// with [1] being the error's index in mderr.Array
+// lazy values are computed, and pointers and structs are followed, to cut what they hold
+// the metadata and errors passed to the Logger are never changed, and
+// entries at levels the Logger doesn't write aren't cut, so their
+// lazy metadata isn't computed
+func WithLimits(lgr Logger, cfg LimitConfig) Logger {
+	if cfg.Key == "" {
+		cfg.Key = "truncated"
+	}
+
+	em := func(lvl Level) ErrMod {
+		return func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+			if Enabled(lgr, ctx, lvl) {
+				_, err, md = cfg.limit("", err, md)
+			}
+
+			f(ctx, err, md)
+		}
+	}
+
+	mm := func(lvl Level) MsgMod {
+		return func(ctx context.Context, msg string, md map[string]any, f MsgFunc) {
+			if Enabled(lgr, ctx, lvl) {
+				msg, _, md = cfg.limit(msg, nil, md)
+			}
+
+			f(ctx, msg, md)
+		}
+	}
+
+	return &Modder{
+		logger: lgr,
+		fatal:  em(Fatal),
+		panic:  em(Panic),
+		error:  em(Error),
+		warn:   mm(Warn),
+		info:   mm(Info),
+		debug:  mm(Debug),
+		trace:  mm(Trace),
+		level: func(ctx context.Context, lvl Level, msg string, md map[string]any, f LvlFunc) {
+			if Enabled(lgr, ctx, lvl) {
+				msg, _, md = cfg.limit(msg, nil, md)
+			}
+
+			f(ctx, lvl, msg, md)
+		},
+	}
+}
+
+// limit cuts the message, the error chain's metadata and the metadata
+func (c LimitConfig) limit(msg string, err error, md map[string]any) (string, error, map[string]any) {
+	lim := &limiter{
+		config: c,
+	}
+
+	if c.MaxStringLength > 0 && len(msg) > c.MaxStringLength {
+		msg = lim.cut(msg, "message")
+	}
+
+	err, _ = lim.error(err, 0)
+
+	val, chg := lim.value(md, "", 1)
+
+	if chg {
+		md = val.(map[string]any)
+	}
+
+	if c.MaxEntrySize > 0 {
+		md = lim.size(len(msg), md)
+	}
+
+	if len(lim.paths) > 0 {
+		cpy := make(map[string]any, len(md)+1)
+
+		for key, val := range md {
+			cpy[key] = val
+		}
+
+		md = c.Collisions.Add(cpy, c.Key, lim.paths)
+	}
+
+	return msg, err, md
+}
+
+// limiter cuts an entry's values and keeps track of the paths it cut
+type limiter struct {
+	config LimitConfig
+	paths  []string
+}
+
+// error cuts the metadata of the error's mderr chain, from the ind'th error down
+// the errors are only copied if something in them, or in their causes, is cut
+// the chain is kept as is from the first error that isn't an mderr
+func (l *limiter) error(err error, ind int) (error, bool) {
+	mde, ok := mderr.AsIs(err)
+
+	if !ok {
+		return err, false
+	}
+
+	val, chg := l.value(mde.Metadata(), "error["+strconv.Itoa(ind)+"]", 1)
+	cau, cch := l.error(mde.Cause(), ind+1)
+
+	if !chg && !cch {
+		return err, false
+	}
+
+	return mderr.Wrap(cau, mde.Message(), val.(map[string]any)), true
+}
+
+// value cuts a value, and its items if it's a map, slice or struct
+// lazy values are computed and pointers are followed to cut what they hold
+// the value is only copied if something in it is cut
+func (l *limiter) value(val any, pth string, dpt int) (any, bool) {
+	switch v := val.(type) {
+	case *md.LazyValue:
+		return l.follow(val, v.Value(), pth, dpt)
+	case string:
+		if l.config.MaxStringLength > 0 && len(v) > l.config.MaxStringLength {
+			return l.cut(v, pth), true
+		}
+
+		return v, false
+	case []byte:
+		if l.config.MaxStringLength > 0 && len(v) > l.config.MaxStringLength {
+			return l.cut(string(v), pth), true
+		}
+
+		return v, false
+	case map[string]any:
+		return l.mapping(v, pth, dpt)
+	case []any:
+		return l.slice(v, pth, dpt)
+	case nil:
+		return v, false
+	}
+
+	rvl := reflect.ValueOf(val)
+
+	switch rvl.Kind() {
+	case reflect.Map:
+		if rvl.Type().Key().Kind() != reflect.String {
+			return val, false
+		}
+
+		mpg := make(map[string]any, rvl.Len())
+		itr := rvl.MapRange()
+
+		for itr.Next() {
+			mpg[itr.Key().String()] = itr.Value().Interface()
+		}
+
+		cut, chg := l.mapping(mpg, pth, dpt)
+
+		if !chg {
+			return val, false
+		}
+
+		return cut, true
+	case reflect.Slice, reflect.Array:
+		sli := make([]any, rvl.Len())
+
+		for i := range sli {
+			sli[i] = rvl.Index(i).Interface()
+		}
+
+		cut, chg := l.slice(sli, pth, dpt)
+
+		if !chg {
+			return val, false
+		}
+
+		return cut, true
+	case reflect.Pointer:
+		if _, ok := val.(json.Marshaler); ok || rvl.IsNil() {
+			return val, false
+		}
+
+		return l.follow(val, rvl.Elem().Interface(), pth, dpt)
+	case reflect.Struct:
+		if _, ok := val.(json.Marshaler); ok {
+			return val, false
+		}
+
+		buf, err := json.Marshal(val)
+
+		if err != nil {
+			return val, false
+		}
+
+		var dec any
+
+		if json.Unmarshal(buf, &dec) != nil {
+			return val, false
+		}
+
+		return l.follow(val, dec, pth, dpt)
+	}
+
+	return val, false
+}
+
+// follow cuts the value held by val, val is kept if nothing is cut
+func (l *limiter) follow(val any, hld any, pth string, dpt int) (any, bool) {
+	cut, chg := l.value(hld, pth, dpt)
+
+	if !chg {
+		return val, false
+	}
+
+	return cut, true
+}
+
+func (l *limiter) mapping(mpg map[string]any, pth string, dpt int) (any, bool) {
+	if l.config.MaxDepth > 0 && dpt > l.config.MaxDepth && len(mpg) > 0 {
+		l.paths = append(l.paths, pth)
+
+		return "…(max depth)", true
+	}
+
+	keys := make([]string, 0, len(mpg))
+
+	for key := range mpg {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	out := make(map[string]any, len(mpg))
+	chg := false
+
+	for i, key := range keys {
+		kpt := key
+
+		if pth != "" {
+			kpt = pth + "." + key
+		}
+
+		if l.config.MaxElements > 0 && i >= l.config.MaxElements {
+			out["…"] = "(+" + strconv.Itoa(len(keys)-i) + " keys)"
+			chg = true
+
+			l.paths = append(l.paths, kpt)
+
+			break
+		}
+
+		cut, vch := l.value(mpg[key], kpt, dpt+1)
+
+		out[key] = cut
+		chg = chg || vch
+	}
+
+	if !chg {
+		return mpg, false
+	}
+
+	return out, true
+}
+
+func (l *limiter) slice(sli []any, pth string, dpt int) (any, bool) {
+	if l.config.MaxDepth > 0 && dpt > l.config.MaxDepth && len(sli) > 0 {
+		l.paths = append(l.paths, pth)
+
+		return "…(max depth)", true
+	}
+
+	out := make([]any, 0, len(sli))
+	chg := false
+
+	for i, val := range sli {
+		ipt := pth + "[" + strconv.Itoa(i) + "]"
+
+		if l.config.MaxElements > 0 && i >= l.config.MaxElements {
+			out = append(out, "…(+"+strconv.Itoa(len(sli)-i)+" items)")
+			chg = true
+
+			l.paths = append(l.paths, ipt)
+
+			break
+		}
+
+		cut, vch := l.value(val, ipt, dpt+1)
+
+		out = append(out, cut)
+		chg = chg || vch
+	}
+
+	if !chg {
+		return sli, false
+	}
+
+	return out, true
+}
+
+// cut cuts a string to the max length, on a utf8 boundary
+func (l *limiter) cut(str string, pth string) string {
+	max := l.config.MaxStringLength
+
+	for max > 0 && !utf8.RuneStart(str[max]) {
+		max--
+	}
+
+	l.paths = append(l.paths, pth)
+
+	return str[:max] + "…(+" + strconv.Itoa(len(str)-max) + " bytes)"
+}
+
+// size replaces the biggest top-level metadata values with markers
+// until the message and metadata fit in the max entry size
+func (l *limiter) size(msz int, md map[string]any) map[string]any {
+	szs := make(map[string]int, len(md))
+	tot := msz
+
+	for key, val := range md {
+		szs[key] = encodedSize(key) + encodedSize(val)
+		tot += szs[key]
+	}
+
+	if tot <= l.config.MaxEntrySize {
+		return md
+	}
+
+	keys := make([]string, 0, len(md))
+
+	for key := range md {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if szs[keys[i]] == szs[keys[j]] {
+			return keys[i] < keys[j]
+		}
+
+		return szs[keys[i]] > szs[keys[j]]
+	})
+
+	out := make(map[string]any, len(md))
+
+	for key, val := range md {
+		out[key] = val
+	}
+
+	for _, key := range keys {
+		if tot <= l.config.MaxEntrySize {
+			break
+		}
+
+		mrk := "…(+" + strconv.Itoa(szs[key]) + " bytes)"
+
+		out[key] = mrk
+		tot += encodedSize(key) + encodedSize(mrk) - szs[key]
+
+		l.paths = append(l.paths, key)
+	}
+
+	return out
+}
+
+// encodedSize gets the size of a value encoded as json
+func encodedSize(val any) int {
+	buf, err := json.Marshal(val)
+
+	if err != nil {
+		return len(fmt.Sprint(val))
+	}
+
+	return len(buf)
+}
@@ -0,0 +1,202 @@
+package mdlog_test
+
+import (
+	"context"
+	"errors"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithLimits(t *testing.T) {
+	var msg string
+	var act map[string]any
+
+	mf := func(_ context.Context, m string, md map[string]any) {
+		msg = m
+		act = md
+	}
+
+	lgr := mdlog.WithLimits(&TestLogger{
+		InfoFunc: mf,
+		ErrorFunc: func(_ context.Context, _ error, md map[string]any) {
+			act = md
+		},
+	}, mdlog.LimitConfig{
+		MaxStringLength: 10,
+		MaxDepth:        3,
+		MaxElements:     5,
+	})
+
+	hdr := http.Header{"Accept": {"a", "b", "c", "d", "e", "f", "g"}}
+	cmd := map[string]any{
+		"body":    strings.Repeat("x", 25),
+		"short":   "short",
+		"headers": hdr,
+		"nested":  map[string]any{"deep": map[string]any{"deeper": map[string]any{"deepest": 1}}},
+		"list":    []any{1, 2, 3, 4, 5, 6},
+	}
+
+	lgr.Info(nil, "ünïcödé message", cmd)
+
+	assert.Equal(t, "ünïcöd…(+10 bytes)", msg)
+	assert.Equal(t, "xxxxxxxxxx…(+15 bytes)", act["body"])
+	assert.Equal(t, "short", act["short"])
+	assert.Equal(t, map[string]any{"Accept": []any{"a", "b", "c", "d", "e", "…(+2 items)"}}, act["headers"])
+	assert.Equal(t, map[string]any{"deep": map[string]any{"deeper": "…(max depth)"}}, act["nested"])
+	assert.Equal(t, []any{1, 2, 3, 4, 5, "…(+1 items)"}, act["list"])
+	assert.ElementsMatch(t, []string{"message", "body", "headers.Accept[5]", "nested.deep.deeper", "list[5]"}, act["truncated"])
+	assert.Equal(t, strings.Repeat("x", 25), cmd["body"])
+	assert.Len(t, hdr["Accept"], 7)
+
+	ok := map[string]any{"short": "short"}
+
+	lgr.Error(nil, mderr.New("error", nil), ok)
+
+	assert.Equal(t, ok, act)
+	assert.NotContains(t, act, "truncated")
+}
+
+func TestWithLimitsEntrySize(t *testing.T) {
+	var act map[string]any
+
+	lgr := mdlog.WithLimits(&TestLogger{
+		InfoFunc: func(_ context.Context, _ string, md map[string]any) {
+			act = md
+		},
+	}, mdlog.LimitConfig{
+		MaxEntrySize: 100,
+		Key:          "cut",
+	})
+
+	lgr.Info(nil, "info", map[string]any{
+		"big":    strings.Repeat("x", 200),
+		"bigger": strings.Repeat("y", 300),
+		"small":  "small",
+	})
+
+	assert.Equal(t, "…(+310 bytes)", act["bigger"])
+	assert.Equal(t, "…(+207 bytes)", act["big"])
+	assert.Equal(t, "small", act["small"])
+	assert.Equal(t, []string{"bigger", "big"}, act["cut"])
+}
+
+func TestWithLimitsError(t *testing.T) {
+	var act error
+	var amd map[string]any
+
+	lgr := mdlog.WithLimits(&TestLogger{
+		ErrorFunc: func(_ context.Context, err error, md map[string]any) {
+			act = err
+			amd = md
+		},
+	}, mdlog.LimitConfig{
+		MaxStringLength: 5,
+	})
+
+	sen := errors.New("sentinel")
+	err := mderr.Wrap(mderr.Wrap(sen, "root", map[string]any{"query": "select * from big"}), "surface", map[string]any{"ok": "ok"})
+
+	lgr.Error(nil, err, nil)
+
+	assert.Equal(t, "surface: root: sentinel", act.Error())
+	assert.Equal(t, map[string]any{"ok": "ok"}, mderr.Metadata(act))
+	assert.Equal(t, "selec…(+12 bytes)", mderr.Metadata(mderr.Cause(act))["query"])
+	assert.ErrorIs(t, act, sen)
+	assert.Equal(t, []string{"error[1].query"}, amd["truncated"])
+	assert.Equal(t, "select * from big", mderr.Metadata(mderr.Cause(err))["query"])
+}
+
+func TestWithLimitsDisabled(t *testing.T) {
+	var act map[string]any
+	var cnt int
+
+	lgr := mdlog.WithLimits(&TestLogger{
+		DebugFunc: func(_ context.Context, _ string, md map[string]any) {
+			act = md
+		},
+		EnabledFunc: func(_ context.Context, lvl mdlog.Level) bool {
+			return mdlog.Info.Allows(lvl)
+		},
+	}, mdlog.LimitConfig{
+		MaxEntrySize: 10,
+	})
+
+	lgr.Debug(nil, "debug", map[string]any{
+		"lazy": md.Lazy(func() any {
+			cnt++
+
+			return strings.Repeat("x", 100)
+		}),
+	})
+
+	assert.Equal(t, 0, cnt)
+	assert.NotContains(t, act, "truncated")
+}
+
+func TestWithLimitsPointers(t *testing.T) {
+	var act map[string]any
+	var cnt int
+
+	lgr := mdlog.WithLimits(&TestLogger{
+		InfoFunc: func(_ context.Context, _ string, md map[string]any) {
+			act = md
+		},
+	}, mdlog.LimitConfig{
+		MaxStringLength: 5,
+		MaxElements:     6,
+	})
+
+	str := "pointer"
+	lst := []string{"a", "b", "c", "d", "e", "f", "g"}
+	bod := &struct {
+		Body string `json:"body"`
+	}{Body: "struct"}
+
+	cmd := map[string]any{
+		"lazy": md.Lazy(func() any {
+			cnt++
+
+			return "lazy value"
+		}),
+		"short":   md.Lazy(func() any { return "short" }),
+		"string":  &str,
+		"list":    &lst,
+		"struct":  bod,
+		"nothing": (*string)(nil),
+	}
+
+	lgr.Info(nil, "info", cmd)
+
+	assert.Equal(t, "lazy …(+5 bytes)", act["lazy"])
+	assert.Equal(t, cmd["short"], act["short"])
+	assert.Equal(t, "point…(+2 bytes)", act["string"])
+	assert.Equal(t, []any{"a", "b", "c", "d", "e", "f", "…(+1 items)"}, act["list"])
+	assert.Equal(t, map[string]any{"body": "struc…(+1 bytes)"}, act["struct"])
+	assert.Nil(t, act["nothing"])
+	assert.ElementsMatch(t, []string{"lazy", "string", "list[6]", "struct.body"}, act["truncated"])
+	assert.Equal(t, 1, cnt)
+	assert.Equal(t, "pointer", str)
+	assert.Len(t, lst, 7)
+}
+
+func TestWithLimitsCollisions(t *testing.T) {
+	var act map[string]any
+
+	lgr := mdlog.WithLimits(&TestLogger{
+		InfoFunc: func(_ context.Context, _ string, md map[string]any) {
+			act = md
+		},
+	}, mdlog.LimitConfig{
+		MaxStringLength: 5,
+		Collisions:      mdlog.CollisionConfig{Policy: mdlog.CallWins},
+	})
+
+	lgr.Info(nil, "info", map[string]any{"truncated": "call", "body": "abcdefgh"})
+
+	assert.Equal(t, map[string]any{"truncated": "call", "body": "abcde…(+3 bytes)"}, act)
+}
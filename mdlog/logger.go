@@ -2,6 +2,7 @@ package mdlog
 
 import (
 	"context"
+	"github.com/chaseisabelle/md/mderr"
 )
 
 // Logger is the interface for all logger implementations
@@ -13,8 +14,110 @@ type Logger interface {
 	Debug(context.Context, string, map[string]any)
 }
 
+// PanicLogger is a Logger that writes panic entries, see LogPanic
+type PanicLogger interface {
+	Panic(context.Context, error, map[string]any)
+}
+
+// TraceLogger is a Logger that writes trace entries, see LogTrace
+type TraceLogger interface {
+	Trace(context.Context, string, map[string]any)
+}
+
+// LevelLogger is a Logger that can write entries at any level,
+// including user-defined levels
+type LevelLogger interface {
+	Logger
+	At(context.Context, Level, string, map[string]any)
+}
+
+// Enabler is a Logger that can tell if entries at a level get written
+// use it to skip building expensive entries, see Enabled
+type Enabler interface {
+	Enabled(context.Context, Level) bool
+}
+
 // ErrFunc is a func that handles an error entry
 type ErrFunc func(context.Context, error, map[string]any)
 
 // MsgFunc is a func that handles a message entry
 type MsgFunc func(context.Context, string, map[string]any)
+
+// LvlFunc is a func that handles an entry at any level
+type LvlFunc func(context.Context, Level, string, map[string]any)
+
+// At writes an entry at the given level
+// built-in levels are written with the matching Logger method,
+// with the message as the error for fatal, panic and error entries
+// user-defined levels are written with At if the Logger is a LevelLogger,
+// otherwise with the Logger method for the level's base
+func At(lgr Logger, ctx context.Context, lvl Level, msg string, md map[string]any) {
+	if !lvl.Builtin() {
+		lvr, ok := lgr.(LevelLogger)
+
+		if ok {
+			lvr.At(ctx, lvl, msg, md)
+
+			return
+		}
+	}
+
+	switch lvl.Base() {
+	case Fatal:
+		lgr.Fatal(ctx, mderr.New(msg, nil), md)
+	case Panic:
+		LogPanic(lgr, ctx, mderr.New(msg, nil), md)
+	case Error:
+		lgr.Error(ctx, mderr.New(msg, nil), md)
+	case Warn:
+		lgr.Warn(ctx, msg, md)
+	case Info:
+		lgr.Info(ctx, msg, md)
+	case Debug:
+		lgr.Debug(ctx, msg, md)
+	default:
+		LogTrace(lgr, ctx, msg, md)
+	}
+}
+
+// LogPanic writes a panic entry to the Logger, then panics
+// Loggers that aren't PanicLoggers get it as an error entry
+func LogPanic(lgr Logger, ctx context.Context, err error, md map[string]any) {
+	pnl, ok := lgr.(PanicLogger)
+
+	if ok {
+		pnl.Panic(ctx, err, md)
+
+		return
+	}
+
+	lgr.Error(ctx, err, md)
+
+	panic(err)
+}
+
+// LogTrace writes a trace entry to the Logger
+// Loggers that aren't TraceLoggers get it as a debug entry
+func LogTrace(lgr Logger, ctx context.Context, msg string, md map[string]any) {
+	trl, ok := lgr.(TraceLogger)
+
+	if ok {
+		trl.Trace(ctx, msg, md)
+
+		return
+	}
+
+	lgr.Debug(ctx, msg, md)
+}
+
+// Enabled checks if the Logger writes entries at the level
+// true if the Logger is not an Enabler
+func Enabled(lgr Logger, ctx context.Context, lvl Level) bool {
+	enb, ok := lgr.(Enabler)
+
+	if !ok {
+		return true
+	}
+
+	return enb.Enabled(ctx, lvl)
+}
@@ -2,21 +2,33 @@ package mdlog_test
 
 import (
 	"context"
+	"github.com/chaseisabelle/md/mderr"
 	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
 )
 
 type TestLogger struct {
 	FatalFunc mdlog.ErrFunc
+	PanicFunc mdlog.ErrFunc
 	ErrorFunc mdlog.ErrFunc
 	WarnFunc  mdlog.MsgFunc
 	InfoFunc  mdlog.MsgFunc
 	DebugFunc mdlog.MsgFunc
+	TraceFunc mdlog.MsgFunc
+	AtFunc    mdlog.LvlFunc
+	// EnabledFunc is optional, all levels are enabled if nil
+	EnabledFunc func(context.Context, mdlog.Level) bool
 }
 
 func (t *TestLogger) Fatal(ctx context.Context, err error, md map[string]any) {
 	t.FatalFunc(ctx, err, md)
 }
 
+func (t *TestLogger) Panic(ctx context.Context, err error, md map[string]any) {
+	t.PanicFunc(ctx, err, md)
+}
+
 func (t *TestLogger) Error(ctx context.Context, err error, md map[string]any) {
 	t.ErrorFunc(ctx, err, md)
 }
@@ -32,3 +44,76 @@ func (t *TestLogger) Info(ctx context.Context, msg string, md map[string]any) {
 func (t *TestLogger) Debug(ctx context.Context, msg string, md map[string]any) {
 	t.DebugFunc(ctx, msg, md)
 }
+
+func (t *TestLogger) Trace(ctx context.Context, msg string, md map[string]any) {
+	t.TraceFunc(ctx, msg, md)
+}
+
+func (t *TestLogger) At(ctx context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+	t.AtFunc(ctx, lvl, msg, md)
+}
+
+func (t *TestLogger) Enabled(ctx context.Context, lvl mdlog.Level) bool {
+	if t.EnabledFunc == nil {
+		return true
+	}
+
+	return t.EnabledFunc(ctx, lvl)
+}
+
+func TestEnabled(t *testing.T) {
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		EnabledFunc: func(_ context.Context, lvl mdlog.Level) bool {
+			return mdlog.Info.Allows(lvl)
+		},
+	}
+
+	lgr = mdlog.WithRequestID(lgr, "")
+	lgr = mdlog.WithErrorTrace(lgr, "")
+
+	assert.True(t, mdlog.Enabled(lgr, context.Background(), mdlog.Error))
+	assert.True(t, mdlog.Enabled(lgr, context.Background(), mdlog.Info))
+	assert.False(t, mdlog.Enabled(lgr, context.Background(), mdlog.Debug))
+	assert.True(t, mdlog.Enabled(struct{ mdlog.Logger }{lgr}, context.Background(), mdlog.Debug))
+}
+
+// LegacyLogger is a five-method Logger, it records its entries as "level:message"
+type LegacyLogger struct {
+	Entries []string
+}
+
+func (l *LegacyLogger) Fatal(_ context.Context, err error, _ map[string]any) {
+	l.Entries = append(l.Entries, "fatal:"+err.Error())
+}
+
+func (l *LegacyLogger) Error(_ context.Context, err error, _ map[string]any) {
+	l.Entries = append(l.Entries, "error:"+err.Error())
+}
+
+func (l *LegacyLogger) Warn(_ context.Context, msg string, _ map[string]any) {
+	l.Entries = append(l.Entries, "warn:"+msg)
+}
+
+func (l *LegacyLogger) Info(_ context.Context, msg string, _ map[string]any) {
+	l.Entries = append(l.Entries, "info:"+msg)
+}
+
+func (l *LegacyLogger) Debug(_ context.Context, msg string, _ map[string]any) {
+	l.Entries = append(l.Entries, "debug:"+msg)
+}
+
+func TestLegacyLogger(t *testing.T) {
+	lgy := &LegacyLogger{}
+	lgr := mdlog.WithRequestID(lgy, "")
+
+	mdlog.LogTrace(lgr, nil, "trace", nil)
+	mdlog.At(lgr, nil, mdlog.Trace, "at trace", nil)
+
+	assert.PanicsWithError(t, "panic", func() {
+		mdlog.LogPanic(lgr, nil, mderr.New("panic", nil), nil)
+	})
+
+	assert.Equal(t, []string{"debug:trace", "debug:at trace", "error:panic"}, lgy.Entries)
+}
@@ -0,0 +1,85 @@
+package mdfile
+
+import (
+	"github.com/chaseisabelle/md/mderr"
+	"time"
+)
+
+// Config is the rotating file config
+type Config struct {
+	// Path is the file path, no file is used if empty
+	Path string `json:"path" yaml:"path"`
+	// MaxSize is the max size in bytes before the file is rotated, 0 for no size rotation
+	MaxSize int64 `json:"max-size" yaml:"max-size"`
+	// Interval is how often the file is rotated, ie "24h", 0 for no time rotation
+	Interval Duration `json:"interval" yaml:"interval"`
+	// MaxAge is how long rotated files are kept, 0 to keep them forever
+	MaxAge Duration `json:"max-age" yaml:"max-age"`
+	// MaxBackups is the max number of rotated files kept, 0 to keep them all
+	MaxBackups int `json:"max-backups" yaml:"max-backups"`
+	// Compress gzips rotated files
+	Compress bool `json:"compress" yaml:"compress"`
+	// ReopenOnSIGHUP reopens the file when the process gets a SIGHUP,
+	// for use with external tools like logrotate
+	ReopenOnSIGHUP bool `json:"reopen-on-sighup" yaml:"reopen-on-sighup"`
+}
+
+// Validate checks every field of the config
+func (c Config) Validate() error {
+	if c.Path == "" {
+		return mderr.New("missing log file path", nil)
+	}
+
+	if c.MaxSize < 0 {
+		return mderr.New("negative log file max size", map[string]any{
+			"max-size": c.MaxSize,
+		})
+	}
+
+	if c.Interval < 0 {
+		return mderr.New("negative log file rotation interval", map[string]any{
+			"interval": c.Interval.String(),
+		})
+	}
+
+	if c.MaxAge < 0 {
+		return mderr.New("negative log file max age", map[string]any{
+			"max-age": c.MaxAge.String(),
+		})
+	}
+
+	if c.MaxBackups < 0 {
+		return mderr.New("negative log file max backups", map[string]any{
+			"max-backups": c.MaxBackups,
+		})
+	}
+
+	return nil
+}
+
+// Duration is a time.Duration that is encoded as text, ie "1h30m"
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(txt []byte) error {
+	dur, err := time.ParseDuration(string(txt))
+
+	if err != nil {
+		return mderr.Wrap(err, "invalid duration", map[string]any{
+			"duration": string(txt),
+		})
+	}
+
+	*d = Duration(dur)
+
+	return nil
+}
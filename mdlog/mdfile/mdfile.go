@@ -0,0 +1,525 @@
+package mdfile
+
+import (
+	"compress/gzip"
+	"github.com/chaseisabelle/md/mderr"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeFormat is the timestamp in rotated file names, ie app-2006-01-02T15-04-05.000000000.log
+const timeFormat = "2006-01-02T15-04-05.000000000"
+
+// File is a rotating log file writer that is safe for concurrent writers
+// the file is rotated by size and/or time, rotated files are named
+// <name>-<timestamp><ext> and optionally gzipped, and old rotated files
+// are removed by age and/or count
+// if a rotation or reopen fails the file is kept open, or reopened on the
+// next write, so a full disk or a permissions error doesn't stop logging
+type File struct {
+	config  Config
+	mutex   sync.Mutex
+	file    *os.File
+	closed  bool
+	size    int64
+	rotate  time.Time
+	mill    chan struct{}
+	signals chan os.Signal
+	done    chan struct{}
+	wait    sync.WaitGroup
+}
+
+// New opens the file, creating it and its directory if needed
+func New(cfg Config) (*File, error) {
+	err := cfg.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{
+		config: cfg,
+		mill:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	err = f.open(time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	f.wait.Add(1)
+
+	go f.miller()
+
+	if cfg.ReopenOnSIGHUP {
+		f.signals = make(chan os.Signal, 1)
+
+		notify(f.signals)
+
+		f.wait.Add(1)
+
+		go f.reopener()
+	}
+
+	f.trigger()
+
+	return f, nil
+}
+
+// Write writes to the file, rotating it first if it's due
+// if the rotation fails the entry is still written to the current file,
+// and the rotation error is returned
+func (f *File) Write(buf []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return 0, mderr.New("log file is closed", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	now := time.Now()
+
+	if f.file == nil {
+		err := f.open(now)
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var rer error
+
+	if f.due(now, len(buf)) {
+		rer = f.rotated(now)
+
+		if f.file == nil {
+			return 0, rer
+		}
+	}
+
+	n, err := f.file.Write(buf)
+
+	f.size += int64(n)
+
+	if err != nil {
+		return n, mderr.Wrap(err, "failed to write to log file", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	return n, rer
+}
+
+// Sync commits the file to disk
+func (f *File) Sync() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Sync()
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to sync log file", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	return nil
+}
+
+// Rotate rotates the file now
+func (f *File) Rotate() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return mderr.New("log file is closed", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	return f.rotated(time.Now())
+}
+
+// Reopen closes and reopens the file at the same path
+// use it after the file was moved by an external tool
+// if the open fails, the next write tries again
+func (f *File) Reopen() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.closed {
+		return mderr.New("log file is closed", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	err := f.close()
+
+	if err != nil {
+		return err
+	}
+
+	return f.open(time.Now())
+}
+
+// Close closes the file and waits for any compression and cleanup to finish
+func (f *File) Close() error {
+	f.mutex.Lock()
+
+	if f.closed {
+		f.mutex.Unlock()
+
+		return nil
+	}
+
+	f.closed = true
+	err := f.close()
+
+	f.mutex.Unlock()
+
+	if f.signals != nil {
+		stop(f.signals)
+	}
+
+	close(f.done)
+
+	f.wait.Wait()
+
+	return err
+}
+
+// due checks if the file needs to be rotated before the write
+func (f *File) due(now time.Time, n int) bool {
+	if f.config.MaxSize > 0 && f.size > 0 && f.size+int64(n) > f.config.MaxSize {
+		return true
+	}
+
+	return !f.rotate.IsZero() && !now.Before(f.rotate)
+}
+
+// rotated renames the file to a timestamped name and opens a new one
+// if the rename fails the file is reopened so writes carry on to it
+// the caller must hold the mutex
+func (f *File) rotated(now time.Time) error {
+	err := f.close()
+
+	if err != nil {
+		_ = f.open(now)
+
+		return err
+	}
+
+	dir, nam, ext := f.parts()
+	bak := filepath.Join(dir, nam+"-"+now.UTC().Format(timeFormat)+ext)
+
+	err = os.Rename(f.config.Path, bak)
+
+	if err != nil && !os.IsNotExist(err) {
+		_ = f.open(now)
+
+		return mderr.Wrap(err, "failed to rename log file", map[string]any{
+			"path":   f.config.Path,
+			"backup": bak,
+		})
+	}
+
+	err = f.open(now)
+
+	if err != nil {
+		return err
+	}
+
+	f.trigger()
+
+	return nil
+}
+
+// open opens the file for appending
+// the caller must hold the mutex, or be New
+func (f *File) open(now time.Time) error {
+	err := os.MkdirAll(filepath.Dir(f.config.Path), 0755)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to create log file directory", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	fil, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to open log file", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	inf, err := fil.Stat()
+
+	if err != nil {
+		_ = fil.Close()
+
+		return mderr.Wrap(err, "failed to stat log file", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	f.file = fil
+	f.size = inf.Size()
+	f.rotate = time.Time{}
+
+	if f.config.Interval > 0 {
+		ivl := time.Duration(f.config.Interval)
+
+		f.rotate = now.Truncate(ivl).Add(ivl)
+	}
+
+	return nil
+}
+
+// close closes the file, if it's open
+// the caller must hold the mutex
+func (f *File) close() error {
+	fil := f.file
+
+	if fil == nil {
+		return nil
+	}
+
+	f.file = nil
+
+	err := fil.Close()
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to close log file", map[string]any{
+			"path": f.config.Path,
+		})
+	}
+
+	return nil
+}
+
+// parts gets the directory, name and extension of the file path
+func (f *File) parts() (string, string, string) {
+	dir := filepath.Dir(f.config.Path)
+	bas := filepath.Base(f.config.Path)
+	ext := filepath.Ext(bas)
+
+	return dir, strings.TrimSuffix(bas, ext), ext
+}
+
+// trigger schedules compression and cleanup of rotated files
+func (f *File) trigger() {
+	select {
+	case f.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (f *File) miller() {
+	defer f.wait.Done()
+
+	for {
+		select {
+		case <-f.done:
+			select {
+			case <-f.mill:
+				_ = f.milled()
+			default:
+			}
+
+			return
+		case <-f.mill:
+			_ = f.milled()
+		}
+	}
+}
+
+func (f *File) reopener() {
+	defer f.wait.Done()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-f.signals:
+			_ = f.Reopen()
+		}
+	}
+}
+
+type backup struct {
+	path string
+	time time.Time
+	gzip bool
+}
+
+// milled compresses and removes rotated files per the config
+// the files are removed after compressing, so they're counted as they end up
+func (f *File) milled() error {
+	bks, err := f.backups()
+
+	if err != nil {
+		return err
+	}
+
+	if f.config.Compress {
+		for _, bak := range bks {
+			if bak.gzip {
+				continue
+			}
+
+			err = compress(bak.path)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		bks, err = f.backups()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	cut := time.Time{}
+
+	if f.config.MaxAge > 0 {
+		cut = time.Now().Add(-time.Duration(f.config.MaxAge))
+	}
+
+	for ind, bak := range bks {
+		old := !cut.IsZero() && bak.time.Before(cut)
+		xtr := f.config.MaxBackups > 0 && ind >= f.config.MaxBackups
+
+		if old || xtr {
+			err = os.Remove(bak.path)
+
+			if err != nil && !os.IsNotExist(err) {
+				return mderr.Wrap(err, "failed to remove rotated log file", map[string]any{
+					"path": bak.path,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// backups gets the rotated files, newest first
+func (f *File) backups() ([]*backup, error) {
+	dir, nam, ext := f.parts()
+	ents, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to read log file directory", map[string]any{
+			"path": dir,
+		})
+	}
+
+	bks := make([]*backup, 0)
+
+	for _, ent := range ents {
+		fnm := ent.Name()
+
+		if ent.IsDir() || !strings.HasPrefix(fnm, nam+"-") {
+			continue
+		}
+
+		gzp := strings.HasSuffix(fnm, ext+".gz")
+		tsp := strings.TrimPrefix(fnm, nam+"-")
+
+		if gzp {
+			tsp = strings.TrimSuffix(tsp, ext+".gz")
+		} else if strings.HasSuffix(tsp, ext) {
+			tsp = strings.TrimSuffix(tsp, ext)
+		} else {
+			continue
+		}
+
+		tim, err := time.Parse(timeFormat, tsp)
+
+		if err != nil {
+			continue
+		}
+
+		bks = append(bks, &backup{
+			path: filepath.Join(dir, fnm),
+			time: tim,
+			gzip: gzp,
+		})
+	}
+
+	sort.Slice(bks, func(i, j int) bool {
+		return bks[i].time.After(bks[j].time)
+	})
+
+	return bks, nil
+}
+
+// compress gzips the file to <path>.gz and removes the original
+func compress(pth string) error {
+	src, err := os.Open(pth)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to open rotated log file", map[string]any{
+			"path": pth,
+		})
+	}
+
+	defer src.Close()
+
+	dst, err := os.OpenFile(pth+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to create compressed log file", map[string]any{
+			"path": pth + ".gz",
+		})
+	}
+
+	gzw := gzip.NewWriter(dst)
+	_, err = io.Copy(gzw, src)
+
+	if err == nil {
+		err = gzw.Close()
+	}
+
+	if err == nil {
+		err = dst.Close()
+	} else {
+		_ = dst.Close()
+	}
+
+	if err != nil {
+		_ = os.Remove(pth + ".gz")
+
+		return mderr.Wrap(err, "failed to compress rotated log file", map[string]any{
+			"path": pth,
+		})
+	}
+
+	_ = src.Close()
+
+	err = os.Remove(pth)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to remove compressed log file", map[string]any{
+			"path": pth,
+		})
+	}
+
+	return nil
+}
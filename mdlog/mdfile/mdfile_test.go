@@ -0,0 +1,348 @@
+package mdfile_test
+
+import (
+	"compress/gzip"
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func files(t *testing.T, dir string) []string {
+	ents, err := os.ReadDir(dir)
+
+	assert.NoError(t, err)
+
+	nms := make([]string, 0, len(ents))
+
+	for _, ent := range ents {
+		nms = append(nms, ent.Name())
+	}
+
+	return nms
+}
+
+func TestSizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	fil, err := mdfile.New(mdfile.Config{
+		Path:    filepath.Join(dir, "app.log"),
+		MaxSize: 10,
+	})
+
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = fil.Write([]byte("12345678\n"))
+
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, fil.Close())
+
+	nms := files(t, dir)
+
+	assert.Len(t, nms, 3)
+	assert.Contains(t, nms, "app.log")
+
+	for _, nam := range nms {
+		buf, err := os.ReadFile(filepath.Join(dir, nam))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "12345678\n", string(buf))
+	}
+}
+
+func TestTimeRotation(t *testing.T) {
+	dir := t.TempDir()
+	fil, err := mdfile.New(mdfile.Config{
+		Path:     filepath.Join(dir, "app.log"),
+		Interval: mdfile.Duration(50 * time.Millisecond),
+	})
+
+	assert.NoError(t, err)
+
+	_, err = fil.Write([]byte("first\n"))
+
+	assert.NoError(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, err = fil.Write([]byte("second\n"))
+
+	assert.NoError(t, err)
+	assert.NoError(t, fil.Close())
+
+	all := ""
+
+	for _, nam := range files(t, dir) {
+		buf, err := os.ReadFile(filepath.Join(dir, nam))
+
+		assert.NoError(t, err)
+
+		if nam == "app.log" {
+			assert.Equal(t, "second\n", string(buf))
+		}
+
+		all += string(buf)
+	}
+
+	assert.Contains(t, all, "first\n")
+}
+
+func TestRetentionAndCompression(t *testing.T) {
+	dir := t.TempDir()
+	fil, err := mdfile.New(mdfile.Config{
+		Path:       filepath.Join(dir, "app.log"),
+		MaxBackups: 2,
+		Compress:   true,
+	})
+
+	assert.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, err = fil.Write([]byte("entry\n"))
+
+		assert.NoError(t, err)
+		assert.NoError(t, fil.Rotate())
+	}
+
+	assert.Eventually(t, func() bool {
+		nms := files(t, dir)
+
+		if len(nms) != 3 {
+			return false
+		}
+
+		for _, nam := range nms {
+			if nam != "app.log" && !strings.HasSuffix(nam, ".log.gz") {
+				return false
+			}
+		}
+
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, fil.Close())
+
+	for _, nam := range files(t, dir) {
+		if nam == "app.log" {
+			continue
+		}
+
+		gzf, err := os.Open(filepath.Join(dir, nam))
+
+		assert.NoError(t, err)
+
+		gzr, err := gzip.NewReader(gzf)
+
+		assert.NoError(t, err)
+
+		buf, err := io.ReadAll(gzr)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "entry\n", string(buf))
+		assert.NoError(t, gzf.Close())
+	}
+}
+
+func TestCloseCompresses(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		dir := t.TempDir()
+		fil, err := mdfile.New(mdfile.Config{
+			Path:       filepath.Join(dir, "app.log"),
+			MaxBackups: 1,
+			Compress:   true,
+		})
+
+		assert.NoError(t, err)
+
+		_, err = fil.Write([]byte("entry\n"))
+
+		assert.NoError(t, err)
+		assert.NoError(t, fil.Rotate())
+		assert.NoError(t, fil.Close())
+
+		nms := files(t, dir)
+
+		assert.Len(t, nms, 2)
+
+		for _, nam := range nms {
+			assert.True(t, nam == "app.log" || strings.HasSuffix(nam, ".log.gz"), nam)
+		}
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app-2000-01-01T00-00-00.000000000.log")
+
+	assert.NoError(t, os.WriteFile(old, []byte("old\n"), 0644))
+
+	fil, err := mdfile.New(mdfile.Config{
+		Path:   filepath.Join(dir, "app.log"),
+		MaxAge: mdfile.Duration(time.Hour),
+	})
+
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(old)
+
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, fil.Close())
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+	fil, err := mdfile.New(mdfile.Config{
+		Path: pth,
+	})
+
+	assert.NoError(t, err)
+
+	_, err = fil.Write([]byte("before\n"))
+
+	assert.NoError(t, err)
+	assert.NoError(t, os.Rename(pth, pth+".1"))
+	assert.NoError(t, fil.Reopen())
+
+	_, err = fil.Write([]byte("after\n"))
+
+	assert.NoError(t, err)
+	assert.NoError(t, fil.Close())
+
+	buf, err := os.ReadFile(pth)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "after\n", string(buf))
+
+	_, err = fil.Write([]byte("closed\n"))
+
+	assert.Error(t, err)
+}
+
+func TestReopenFailure(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	fil, err := mdfile.New(mdfile.Config{
+		Path:           filepath.Join(sub, "app.log"),
+		ReopenOnSIGHUP: true,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, os.RemoveAll(sub))
+	assert.NoError(t, os.WriteFile(sub, nil, 0644))
+	assert.Error(t, fil.Reopen())
+	assert.Error(t, fil.Rotate())
+
+	_, err = fil.Write([]byte("lost\n"))
+
+	assert.Error(t, err)
+	assert.NoError(t, os.Remove(sub))
+
+	_, err = fil.Write([]byte("kept\n"))
+
+	assert.NoError(t, err)
+	assert.NoError(t, fil.Close())
+	assert.NoError(t, fil.Close())
+
+	_, err = fil.Write([]byte("closed\n"))
+
+	assert.Error(t, err)
+
+	buf, err := os.ReadFile(filepath.Join(sub, "app.log"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "kept\n", string(buf))
+}
+
+func TestCloseAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	cfg := mdfile.Config{
+		Path:           filepath.Join(sub, "app.log"),
+		ReopenOnSIGHUP: true,
+	}
+
+	fil, err := mdfile.New(cfg) //<< starts the runtime's signal goroutine
+
+	assert.NoError(t, err)
+	assert.NoError(t, fil.Close())
+
+	cnt := runtime.NumGoroutine()
+	fil, err = mdfile.New(cfg)
+
+	assert.NoError(t, err)
+	assert.Greater(t, runtime.NumGoroutine(), cnt)
+	assert.NoError(t, os.RemoveAll(sub))
+	assert.NoError(t, os.WriteFile(sub, nil, 0644))
+	assert.Error(t, fil.Reopen())
+	assert.NoError(t, fil.Close())
+
+	ddl := time.Now().Add(time.Second)
+
+	for runtime.NumGoroutine() > cnt && time.Now().Before(ddl) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, cnt, runtime.NumGoroutine())
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	fil, err := mdfile.New(mdfile.Config{
+		Path:    filepath.Join(dir, "app.log"),
+		MaxSize: 100,
+	})
+
+	assert.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				_, err := fil.Write([]byte("0123456789\n"))
+
+				assert.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.NoError(t, fil.Close())
+
+	tot := 0
+
+	for _, nam := range files(t, dir) {
+		buf, err := os.ReadFile(filepath.Join(dir, nam))
+
+		assert.NoError(t, err)
+
+		tot += strings.Count(string(buf), "0123456789\n")
+	}
+
+	assert.Equal(t, 1000, tot)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, mdfile.Config{}.Validate())
+	assert.Error(t, mdfile.Config{Path: "app.log", MaxSize: -1}.Validate())
+	assert.Error(t, mdfile.Config{Path: "app.log", MaxBackups: -1}.Validate())
+	assert.Error(t, mdfile.Config{Path: "app.log", MaxAge: -1}.Validate())
+	assert.NoError(t, mdfile.Config{Path: "app.log"}.Validate())
+}
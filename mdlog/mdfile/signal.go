@@ -0,0 +1,17 @@
+//go:build !windows
+
+package mdfile
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func notify(sig chan os.Signal) {
+	signal.Notify(sig, syscall.SIGHUP)
+}
+
+func stop(sig chan os.Signal) {
+	signal.Stop(sig)
+}
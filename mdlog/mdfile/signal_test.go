@@ -0,0 +1,34 @@
+//go:build !windows
+
+package mdfile_test
+
+import (
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReopenOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "app.log")
+	fil, err := mdfile.New(mdfile.Config{
+		Path:           pth,
+		ReopenOnSIGHUP: true,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, os.Rename(pth, pth+".1"))
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(pth)
+
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, fil.Close())
+}
@@ -0,0 +1,10 @@
+package mdfile
+
+import (
+	"os"
+)
+
+// there is no SIGHUP on windows, so the file is never reopened by signal
+func notify(chan os.Signal) {}
+
+func stop(chan os.Signal) {}
@@ -0,0 +1,275 @@
+package mdsentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is the sentry client name sent in the auth header
+const Client = "mdsentry/1.0"
+
+// Config is the Transport config
+type Config struct {
+	// DSN is the sentry project dsn, ie https://<key>@<host>/<project>
+	DSN string
+	// Environment is the event environment, ie "prod"
+	Environment string
+	// Release is the event release, ie a version or commit
+	Release string
+	// ServerName is the event server name, defaults to none
+	ServerName string
+	// Client is the http client used to send envelopes, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// Transport is an mdlog.Transport that sends reports to sentry
+// as envelopes, one event per envelope
+type Transport struct {
+	config   Config
+	endpoint string
+	auth     string
+}
+
+// New creates a Transport from the config
+func New(cfg Config) (*Transport, error) {
+	dsn, err := url.Parse(cfg.DSN)
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to parse sentry dsn", nil)
+	}
+
+	if dsn.Scheme != "http" && dsn.Scheme != "https" {
+		return nil, mderr.New("invalid sentry dsn scheme", map[string]any{
+			"scheme": dsn.Scheme,
+		})
+	}
+
+	key := dsn.User.Username()
+
+	if key == "" {
+		return nil, mderr.New("missing sentry dsn public key", nil)
+	}
+
+	pth := strings.TrimSuffix(dsn.Path, "/")
+	ind := strings.LastIndex(pth, "/")
+	pid := pth[ind+1:]
+
+	if pid == "" {
+		return nil, mderr.New("missing sentry dsn project id", nil)
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	end := url.URL{
+		Scheme: dsn.Scheme,
+		Host:   dsn.Host,
+		Path:   fmt.Sprintf("%s/api/%s/envelope/", pth[:ind], pid),
+	}
+
+	return &Transport{
+		config:   cfg,
+		endpoint: end.String(),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", Client, key),
+	}, nil
+}
+
+// Send sends each report as an event envelope
+// all the reports are sent even if some fail, the first error is returned
+func (t *Transport) Send(ctx context.Context, rps []*mdlog.Report) error {
+	var res error
+
+	fld := 0
+
+	for _, rpt := range rps {
+		err := t.send(ctx, rpt)
+
+		if err != nil {
+			fld++
+
+			if res == nil {
+				res = err
+			}
+		}
+	}
+
+	if res != nil {
+		return mderr.Wrap(res, "failed to send sentry envelopes", map[string]any{
+			"failed":  fld,
+			"reports": len(rps),
+		})
+	}
+
+	return nil
+}
+
+func (t *Transport) send(ctx context.Context, rpt *mdlog.Report) error {
+	eid := strings.ReplaceAll(uuid.New().String(), "-", "")
+	bod, err := t.envelope(eid, rpt)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(bod))
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to create sentry request", nil)
+	}
+
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", t.auth)
+
+	res, err := t.config.Client.Do(req)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to send sentry request", map[string]any{
+			"event-id": eid,
+		})
+	}
+
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return mderr.New("unexpected sentry response status", map[string]any{
+			"event-id": eid,
+			"status":   res.StatusCode,
+		})
+	}
+
+	return nil
+}
+
+// envelope encodes the report as a sentry envelope with one event item
+// https://develop.sentry.dev/sdk/envelopes/
+func (t *Transport) envelope(eid string, rpt *mdlog.Report) ([]byte, error) {
+	evt, err := json.Marshal(t.event(eid, rpt))
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to encode sentry event", map[string]any{
+			"event-id": eid,
+		})
+	}
+
+	hdr, err := json.Marshal(map[string]any{
+		"event_id": eid,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      t.config.DSN,
+	})
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to encode sentry envelope header", nil)
+	}
+
+	itm, err := json.Marshal(map[string]any{
+		"type":         "event",
+		"length":       len(evt),
+		"content_type": "application/json",
+	})
+
+	if err != nil {
+		return nil, mderr.Wrap(err, "failed to encode sentry item header", nil)
+	}
+
+	buf := bytes.Buffer{}
+
+	buf.Write(hdr)
+	buf.WriteByte('\n')
+	buf.Write(itm)
+	buf.WriteByte('\n')
+	buf.Write(evt)
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// event converts a report to a sentry event
+// the exceptions are the error stack, root cause first as sentry expects,
+// and the events are grouped by the error stack's messages
+func (t *Transport) event(eid string, rpt *mdlog.Report) map[string]any {
+	arr := mderr.RArray(rpt.Error)
+	exs := make([]map[string]any, len(arr))
+	fps := make([]string, len(arr))
+
+	for ind, err := range arr {
+		exs[ind] = map[string]any{
+			"type":  fmt.Sprintf("%T", err),
+			"value": mderr.Message(err),
+		}
+
+		fps[len(arr)-1-ind] = mderr.Message(err)
+	}
+
+	tgs := map[string]string{}
+
+	if rpt.RequestID != "" {
+		tgs["request_id"] = rpt.RequestID
+	}
+
+	ext := map[string]any{
+		"error-trace": rpt.Stack,
+	}
+
+	for key, val := range rpt.Metadata {
+		ext[key] = val
+	}
+
+	evt := map[string]any{
+		"event_id":  eid,
+		"timestamp": float64(rpt.Time.UnixNano()) / float64(time.Second),
+		"platform":  "go",
+		"logger":    "mdlog",
+		"level":     level(rpt.Level),
+		"message": map[string]any{
+			"formatted": mderr.Error(rpt.Error),
+		},
+		"exception": map[string]any{
+			"values": exs,
+		},
+		"fingerprint": fps,
+		"tags":        tgs,
+		"extra":       ext,
+	}
+
+	if t.config.Environment != "" {
+		evt["environment"] = t.config.Environment
+	}
+
+	if t.config.Release != "" {
+		evt["release"] = t.config.Release
+	}
+
+	if t.config.ServerName != "" {
+		evt["server_name"] = t.config.ServerName
+	}
+
+	return evt
+}
+
+func level(lvl mdlog.Level) string {
+	switch lvl.Base() {
+	case mdlog.Fatal, mdlog.Panic:
+		return "fatal"
+	case mdlog.Error:
+		return "error"
+	case mdlog.Warn:
+		return "warning"
+	case mdlog.Info:
+		return "info"
+	default:
+		return "debug"
+	}
+}
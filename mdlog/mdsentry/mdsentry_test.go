@@ -0,0 +1,120 @@
+package mdsentry_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdsentry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	var lns [][]map[string]any
+	var hdr http.Header
+	var pth string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hdr = r.Header
+		pth = r.URL.Path
+
+		var env []map[string]any
+
+		scn := bufio.NewScanner(r.Body)
+
+		for scn.Scan() {
+			var lin map[string]any
+
+			assert.NoError(t, json.Unmarshal(scn.Bytes(), &lin))
+
+			env = append(env, lin)
+		}
+
+		lns = append(lns, env)
+	}))
+
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42"
+	tpt, err := mdsentry.New(mdsentry.Config{
+		DSN:         dsn,
+		Environment: "test",
+	})
+
+	assert.NoError(t, err)
+
+	cause := fmt.Errorf("root error")
+	rpt := &mdlog.Report{
+		Time:      time.Now(),
+		Level:     mdlog.Error,
+		Error:     mderr.Wrap(cause, "surface error", nil),
+		RequestID: "1234",
+		Metadata: map[string]any{
+			"foo": "bar",
+		},
+	}
+
+	assert.NoError(t, tpt.Send(context.Background(), []*mdlog.Report{rpt, rpt}))
+	assert.Len(t, lns, 2)
+	assert.Equal(t, "/api/42/envelope/", pth)
+	assert.Equal(t, "application/x-sentry-envelope", hdr.Get("Content-Type"))
+	assert.Contains(t, hdr.Get("X-Sentry-Auth"), "sentry_key=public")
+
+	env := lns[0]
+
+	assert.Len(t, env, 3)
+	assert.Equal(t, dsn, env[0]["dsn"])
+	assert.Equal(t, "event", env[1]["type"])
+
+	evt := env[2]
+
+	assert.Equal(t, env[0]["event_id"], evt["event_id"])
+	assert.Equal(t, "error", evt["level"])
+	assert.Equal(t, "test", evt["environment"])
+	assert.Equal(t, map[string]any{"request_id": "1234"}, evt["tags"])
+	assert.Equal(t, "bar", evt["extra"].(map[string]any)["foo"])
+	assert.Equal(t, []any{"surface error", "root error"}, evt["fingerprint"])
+
+	exs := evt["exception"].(map[string]any)["values"].([]any)
+
+	assert.Len(t, exs, 2)
+	assert.Equal(t, "root error", exs[0].(map[string]any)["value"])
+	assert.Equal(t, "surface error", exs[1].(map[string]any)["value"])
+}
+
+func TestTransportStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	defer srv.Close()
+
+	tpt, err := mdsentry.New(mdsentry.Config{
+		DSN: strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42",
+	})
+
+	assert.NoError(t, err)
+
+	err = tpt.Send(context.Background(), []*mdlog.Report{{
+		Time:  time.Now(),
+		Level: mdlog.Error,
+		Error: mderr.New("error", nil),
+	}})
+
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	for _, dsn := range []string{"", "ftp://key@host/1", "https://host/1", "https://key@host/"} {
+		_, err := mdsentry.New(mdsentry.Config{DSN: dsn})
+
+		assert.Error(t, err, dsn)
+	}
+}
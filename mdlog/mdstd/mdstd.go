@@ -0,0 +1,306 @@
+package mdstd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Std is a logger that only uses the standard library
+// entries have the same schema as mdzero's, ie
+//
+//	{"level":"info","time":1700000000,"message":"hello","metadata":{"foo":"bar"}}
+//
+// error entries have an "error" field instead of a "message" field
+type Std struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	level   mdlog.Level
+	closer  io.Closer
+	console bool
+	caller  bool
+	mutex   sync.Mutex
+}
+
+func init() {
+	mdlog.Register("std", func(cfg mdlog.Config) (mdlog.Logger, error) {
+		return New(cfg)
+	})
+
+	mdlog.Register("console", func(cfg mdlog.Config) (mdlog.Logger, error) {
+		return NewConsole(cfg)
+	})
+}
+
+// New creates a logger that writes json lines
+func New(cfg mdlog.Config) (*Std, error) {
+	return newStd(cfg, false)
+}
+
+// NewConsole creates a logger that writes human-readable lines, ie
+//
+//	2006-01-02T15:04:05Z07:00 INFO hello foo=bar
+func NewConsole(cfg mdlog.Config) (*Std, error) {
+	return newStd(cfg, true)
+}
+
+func newStd(cfg mdlog.Config, cns bool) (*Std, error) {
+	lvl := cfg.Level
+
+	if !lvl.Valid() {
+		return nil, mderr.New("invalid log level", map[string]any{
+			"level": int(lvl),
+		})
+	}
+
+	sow, sew, cls, err := cfg.Outputs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Std{
+		stdout:  sow,
+		stderr:  sew,
+		level:   lvl,
+		closer:  cls,
+		console: cns,
+		caller:  cfg.Caller,
+	}, nil
+}
+
+// Close closes the log file, if there is one
+func (s *Std) Close() error {
+	return s.closer.Close()
+}
+
+func (s *Std) Fatal(ctx context.Context, err error, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Fatal, err, "", md))
+
+	os.Exit(1)
+}
+
+func (s *Std) Panic(ctx context.Context, err error, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Panic, err, "", md))
+
+	panic(err)
+}
+
+func (s *Std) Error(ctx context.Context, err error, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Error, err, "", md))
+}
+
+func (s *Std) Warn(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Warn, nil, msg, md))
+}
+
+func (s *Std) Info(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Info, nil, msg, md))
+}
+
+func (s *Std) Debug(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Debug, nil, msg, md))
+}
+
+func (s *Std) Trace(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Trace, nil, msg, md))
+}
+
+// Enabled checks if entries at the level get written
+func (s *Std) Enabled(ctx context.Context, lvl mdlog.Level) bool {
+	return s.level.AllowsContext(ctx, lvl)
+}
+
+// At writes an entry at any level
+func (s *Std) At(ctx context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+	s.Log(mdlog.NewEntry(ctx, lvl, nil, msg, md))
+}
+
+// Log writes a whole entry
+// the entry's name is written as the "logger" field
+// fatal entries exit and panic entries panic, like the Logger methods
+func (s *Std) Log(ent mdlog.Entry) {
+	s.write(ent)
+
+	switch ent.Level.Base() {
+	case mdlog.Fatal:
+		os.Exit(1)
+	case mdlog.Panic:
+		if ent.Error != nil {
+			panic(ent.Error)
+		}
+
+		panic(ent.Message)
+	}
+}
+
+// write writes an entry as a single line
+// fatal, panic and error entries go to stderr, the rest go to stdout
+func (s *Std) write(ent mdlog.Entry) {
+	if !s.Enabled(ent.Context, ent.Level) {
+		return
+	}
+
+	if ent.Time.IsZero() {
+		ent.Time = time.Now()
+	}
+
+	if ent.Caller == "" && s.caller {
+		ent.Caller = mdlog.Caller(ent.Context)
+	}
+
+	var buf []byte
+
+	if s.console {
+		buf = console(ent)
+	} else {
+		buf = jsonLine(ent)
+	}
+
+	wtr := s.stdout
+
+	if mdlog.Error.Allows(ent.Level.Base()) {
+		wtr = s.stderr
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, _ = wtr.Write(buf)
+}
+
+// jsonLine encodes an entry with mdzero's schema
+// metadata that can't be encoded is written as the encoding error
+func jsonLine(ent mdlog.Entry) []byte {
+	buf := bytes.NewBufferString(`{"level":`)
+
+	buf.Write(jsonString(ent.Level.String()))
+	buf.WriteString(`,"time":`)
+	buf.WriteString(strconv.FormatInt(ent.Time.Unix(), 10))
+
+	if ent.Name != "" {
+		buf.WriteString(`,"logger":`)
+		buf.Write(jsonString(ent.Name))
+	}
+
+	if ent.Caller != "" {
+		buf.WriteString(`,"caller":`)
+		buf.Write(jsonString(ent.Caller))
+	}
+
+	if ent.Error != nil {
+		buf.WriteString(`,"error":`)
+		buf.Write(jsonString(ent.Error.Error()))
+	}
+
+	if ent.Message != "" {
+		buf.WriteString(`,"message":`)
+		buf.Write(jsonString(ent.Message))
+	}
+
+	mdb, mde := json.Marshal(ent.Metadata)
+
+	if mde != nil {
+		mdb = jsonString(mde.Error())
+	}
+
+	buf.WriteString(`,"metadata":`)
+	buf.Write(mdb)
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// console formats an entry for humans
+// metadata is written as sorted key=value pairs
+func console(ent mdlog.Entry) []byte {
+	buf := bytes.NewBufferString(ent.Time.Format(time.RFC3339))
+
+	buf.WriteString(" ")
+	buf.WriteString(strings.ToUpper(ent.Level.String()))
+
+	if ent.Name != "" {
+		buf.WriteString(" [")
+		buf.WriteString(ent.Name)
+		buf.WriteString("]")
+	}
+
+	if ent.Caller != "" {
+		buf.WriteString(" ")
+		buf.WriteString(ent.Caller)
+	}
+
+	if ent.Message != "" {
+		buf.WriteString(" ")
+		buf.WriteString(ent.Message)
+	}
+
+	if ent.Error != nil {
+		buf.WriteString(" error=")
+		buf.WriteString(value(ent.Error.Error()))
+	}
+
+	md := ent.Metadata
+	keys := make([]string, 0, len(md))
+
+	for key := range md {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		buf.WriteString(" ")
+		buf.WriteString(key)
+		buf.WriteString("=")
+		buf.WriteString(value(md[key]))
+	}
+
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
+
+// value formats a console metadata value
+// strings are quoted if they have spaces, quotes or control chars,
+// and everything else that isn't a basic value is written as json
+func value(val any) string {
+	switch v := val.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, " \"=\t\r\n") || strconv.Quote(v) != `"`+v+`"` {
+			return strconv.Quote(v)
+		}
+
+		return v
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case error:
+		return value(v.Error())
+	case fmt.Stringer:
+		return value(v.String())
+	}
+
+	buf, err := json.Marshal(val)
+
+	if err != nil {
+		return value(fmt.Sprintf("%+v", val))
+	}
+
+	return string(buf)
+}
+
+func jsonString(str string) []byte {
+	buf, _ := json.Marshal(str)
+
+	return buf
+}
@@ -0,0 +1,110 @@
+package mdstd_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"github.com/chaseisabelle/md/mdlog/mdstd"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func entries(t *testing.T, pth string) []map[string]any {
+	fil, err := os.Open(pth)
+
+	assert.NoError(t, err)
+
+	defer fil.Close()
+
+	var ens []map[string]any
+
+	scn := bufio.NewScanner(fil)
+
+	for scn.Scan() {
+		var ent map[string]any
+
+		assert.NoError(t, json.Unmarshal(scn.Bytes(), &ent))
+
+		ens = append(ens, ent)
+	}
+
+	return ens
+}
+
+func TestStd(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "app.log")
+	lgr, err := mdstd.New(mdlog.Config{
+		Level: mdlog.Info,
+		File: mdfile.Config{
+			Path: pth,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	lgr.Error(ctx, mderr.New("error", nil), map[string]any{"foo": "bar"})
+	lgr.Warn(ctx, "warn", nil)
+	lgr.Info(ctx, "info", map[string]any{"bad": func() {}})
+	lgr.Debug(ctx, "debug", nil)
+	lgr.Trace(ctx, "trace", nil)
+
+	assert.Panics(t, func() {
+		lgr.Panic(ctx, mderr.New("panic", nil), nil)
+	})
+
+	assert.True(t, lgr.Enabled(ctx, mdlog.Info))
+	assert.False(t, lgr.Enabled(ctx, mdlog.Debug))
+	assert.NoError(t, lgr.Close())
+
+	ens := entries(t, pth)
+
+	assert.Len(t, ens, 4)
+	assert.Equal(t, "error", ens[0]["level"])
+	assert.Equal(t, "error", ens[0]["error"])
+	assert.NotContains(t, ens[0], "message")
+	assert.IsType(t, float64(0), ens[0]["time"])
+	assert.Equal(t, map[string]any{"foo": "bar"}, ens[0]["metadata"])
+	assert.Equal(t, "warn", ens[1]["level"])
+	assert.Equal(t, "warn", ens[1]["message"])
+	assert.Nil(t, ens[1]["metadata"])
+	assert.Equal(t, "info", ens[2]["level"])
+	assert.IsType(t, "", ens[2]["metadata"])
+	assert.Equal(t, "panic", ens[3]["level"])
+}
+
+func TestConsole(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "app.log")
+	lgr, err := mdstd.NewConsole(mdlog.Config{
+		Level: mdlog.Info,
+		File: mdfile.Config{
+			Path: pth,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	lgr.Error(ctx, mderr.New("bad thing", nil), map[string]any{"foo": "bar", "num": 1})
+	lgr.Info(ctx, "info", map[string]any{"list": []int{1, 2}})
+
+	assert.NoError(t, lgr.Close())
+
+	buf, err := os.ReadFile(pth)
+
+	assert.NoError(t, err)
+
+	lns := strings.Split(strings.TrimSpace(string(buf)), "\n")
+
+	assert.Len(t, lns, 2)
+	assert.True(t, strings.HasSuffix(lns[0], ` ERROR error="bad thing" foo=bar num=1`))
+	assert.True(t, strings.HasSuffix(lns[1], ` INFO info list=[1,2]`))
+}
@@ -0,0 +1,489 @@
+package mdsyslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syslog severities
+// https://www.rfc-editor.org/rfc/rfc5424#section-6.2.1
+const (
+	Emergency = iota
+	Alert
+	Critical
+	Err
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+// Enterprise is the default private enterprise number for the STRUCTURED-DATA ids
+// 32473 is reserved for documentation, use your own if you have one
+const Enterprise = 32473
+
+// Config is the syslog logger config
+type Config struct {
+	// Network is "udp", "tcp", "unix" or "unixgram"
+	// if empty the local syslog socket is used
+	Network string
+	// Address is the host:port or socket path
+	Address string
+	// Level is the most verbose level that gets written
+	Level mdlog.Level
+	// Facility is the syslog facility, 0 (kern) to 23 (local7)
+	// defaults to 1 (user-level) if nil
+	Facility *int
+	// AppName is the APP-NAME, defaults to the executable name
+	AppName string
+	// Hostname is the HOSTNAME, defaults to os.Hostname
+	Hostname string
+	// Enterprise is the private enterprise number for the STRUCTURED-DATA ids,
+	// defaults to Enterprise
+	Enterprise int
+	// Severities overrides the syslog severity for levels, ie a user-defined notice level
+	// levels not in here use their base level's severity
+	Severities map[mdlog.Level]int
+}
+
+// Syslog is an mdlog.Logger that writes RFC 5424 messages to syslog
+// metadata is written as the md@<enterprise> STRUCTURED-DATA element,
+// the request id as the request@<enterprise> element, and the logger name
+// and caller as the log@<enterprise> element
+type Syslog struct {
+	config   Config
+	facility int
+	mutex    sync.Mutex
+	conn     net.Conn
+	pid      string
+}
+
+// New connects to syslog
+func New(cfg Config) (*Syslog, error) {
+	if !cfg.Level.Valid() {
+		return nil, mderr.New("invalid log level", map[string]any{
+			"level": int(cfg.Level),
+		})
+	}
+
+	switch cfg.Network {
+	case "", "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, mderr.New("invalid syslog network", map[string]any{
+			"network": cfg.Network,
+		})
+	}
+
+	if cfg.Network != "" && cfg.Address == "" {
+		return nil, mderr.New("missing syslog address", map[string]any{
+			"network": cfg.Network,
+		})
+	}
+
+	fac := 1
+
+	if cfg.Facility != nil {
+		fac = *cfg.Facility
+	}
+
+	if fac < 0 || fac > 23 {
+		return nil, mderr.New("invalid syslog facility", map[string]any{
+			"facility": fac,
+		})
+	}
+
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+
+	if cfg.Enterprise == 0 {
+		cfg.Enterprise = Enterprise
+	}
+
+	s := &Syslog{
+		config:   cfg,
+		facility: fac,
+		pid:      fmt.Sprint(os.Getpid()),
+	}
+
+	err := s.connect()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Syslog) Fatal(ctx context.Context, err error, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Fatal, err, "", md))
+
+	os.Exit(1)
+}
+
+func (s *Syslog) Panic(ctx context.Context, err error, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Panic, err, "", md))
+
+	panic(err)
+}
+
+func (s *Syslog) Error(ctx context.Context, err error, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Error, err, "", md))
+}
+
+func (s *Syslog) Warn(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Warn, nil, msg, md))
+}
+
+func (s *Syslog) Info(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Info, nil, msg, md))
+}
+
+func (s *Syslog) Debug(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Debug, nil, msg, md))
+}
+
+func (s *Syslog) Trace(ctx context.Context, msg string, md map[string]any) {
+	s.write(mdlog.NewEntry(ctx, mdlog.Trace, nil, msg, md))
+}
+
+// Enabled checks if entries at the level get written
+func (s *Syslog) Enabled(ctx context.Context, lvl mdlog.Level) bool {
+	return s.config.Level.AllowsContext(ctx, lvl)
+}
+
+// At writes an entry at any level
+func (s *Syslog) At(ctx context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+	s.Log(mdlog.NewEntry(ctx, lvl, nil, msg, md))
+}
+
+// Log writes a whole entry with the entry's time
+// the entry's name and caller are written as the log@<enterprise> element
+// fatal entries exit and panic entries panic, like the Logger methods
+func (s *Syslog) Log(ent mdlog.Entry) {
+	s.write(ent)
+
+	switch ent.Level.Base() {
+	case mdlog.Fatal:
+		os.Exit(1)
+	case mdlog.Panic:
+		if ent.Error != nil {
+			panic(ent.Error)
+		}
+
+		panic(ent.Message)
+	}
+}
+
+// Close closes the syslog connection
+func (s *Syslog) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+
+	s.conn = nil
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to close syslog connection", nil)
+	}
+
+	return nil
+}
+
+// Severity gets the syslog severity for a level
+func (s *Syslog) Severity(lvl mdlog.Level) int {
+	sev, ok := s.config.Severities[lvl]
+
+	if ok {
+		return sev
+	}
+
+	switch lvl.Base() {
+	case mdlog.Fatal, mdlog.Panic:
+		return Critical
+	case mdlog.Error:
+		return Err
+	case mdlog.Warn:
+		return Warning
+	case mdlog.Info:
+		return Informational
+	default:
+		return Debug
+	}
+}
+
+// write formats and sends the entry, reconnecting once if the send fails
+// send errors are written to stderr since there is nowhere else to log them
+func (s *Syslog) write(ent mdlog.Entry) {
+	if !s.Enabled(ent.Context, ent.Level) {
+		return
+	}
+
+	if ent.Time.IsZero() {
+		ent.Time = time.Now()
+	}
+
+	if ent.Error != nil {
+		ent.Message = ent.Error.Error()
+	}
+
+	buf := s.format(ent)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.send(buf)
+
+	if err != nil {
+		err = s.connect()
+
+		if err == nil {
+			err = s.send(buf)
+		}
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "mdsyslog: %s\n", mderr.Error(err))
+	}
+}
+
+// send writes the message to the connection
+// stream connections use octet-counting framing (RFC 6587)
+// the caller must hold the mutex
+func (s *Syslog) send(buf []byte) error {
+	if s.conn == nil {
+		return mderr.New("syslog connection is closed", nil)
+	}
+
+	switch s.conn.(type) {
+	case *net.TCPConn:
+		buf = append([]byte(fmt.Sprintf("%d ", len(buf))), buf...)
+	case *net.UnixConn:
+		if s.network() == "unix" {
+			buf = append([]byte(fmt.Sprintf("%d ", len(buf))), buf...)
+		}
+	}
+
+	_, err := s.conn.Write(buf)
+
+	if err != nil {
+		return mderr.Wrap(err, "failed to write to syslog", nil)
+	}
+
+	return nil
+}
+
+// connect (re)connects to syslog
+// the caller must hold the mutex, or be New
+func (s *Syslog) connect() error {
+	if s.conn != nil {
+		_ = s.conn.Close()
+
+		s.conn = nil
+	}
+
+	if s.config.Network != "" {
+		con, err := net.Dial(s.config.Network, s.config.Address)
+
+		if err != nil {
+			return mderr.Wrap(err, "failed to connect to syslog", map[string]any{
+				"network": s.config.Network,
+				"address": s.config.Address,
+			})
+		}
+
+		s.conn = con
+
+		return nil
+	}
+
+	for _, nwk := range []string{"unixgram", "unix"} {
+		for _, pth := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+			con, err := net.Dial(nwk, pth)
+
+			if err == nil {
+				s.conn = con
+
+				return nil
+			}
+		}
+	}
+
+	return mderr.New("failed to connect to local syslog", nil)
+}
+
+func (s *Syslog) network() string {
+	if s.conn == nil {
+		return ""
+	}
+
+	return s.conn.RemoteAddr().Network()
+}
+
+// format formats an RFC 5424 message
+// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+// the level name is used as the MSGID
+func (s *Syslog) format(ent mdlog.Entry) []byte {
+	pri := s.facility*8 + s.Severity(ent.Level)
+	sds := ""
+
+	if rid := mdctx.RequestID(ent.Context); rid != "" {
+		sds += element(fmt.Sprintf("request@%d", s.config.Enterprise), map[string]any{
+			"id": rid,
+		})
+	}
+
+	if ent.Name != "" || ent.Caller != "" {
+		lmd := map[string]any{}
+
+		if ent.Name != "" {
+			lmd["name"] = ent.Name
+		}
+
+		if ent.Caller != "" {
+			lmd["caller"] = ent.Caller
+		}
+
+		sds += element(fmt.Sprintf("log@%d", s.config.Enterprise), lmd)
+	}
+
+	if len(ent.Metadata) > 0 {
+		sds += element(fmt.Sprintf("md@%d", s.config.Enterprise), ent.Metadata)
+	}
+
+	if sds == "" {
+		sds = "-"
+	}
+
+	buf := fmt.Sprintf("<%d>1 %s %s %s %s %s %s",
+		pri,
+		ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		header(s.config.Hostname, 255),
+		header(s.config.AppName, 48),
+		header(s.pid, 128),
+		header(ent.Level.String(), 32),
+		sds,
+	)
+
+	if ent.Message != "" {
+		buf += " " + ent.Message
+	}
+
+	return []byte(buf)
+}
+
+// element formats an SD-ELEMENT with the metadata as its SD-PARAMs
+// in key order, with lazy values computed and non-string values as json
+func element(sid string, emd map[string]any) string {
+	res := md.Resolve(emd)
+	kys := make([]string, 0, len(res))
+
+	for key := range res {
+		kys = append(kys, key)
+	}
+
+	sort.Strings(kys)
+
+	buf := strings.Builder{}
+
+	buf.WriteString("[" + sid)
+
+	for _, key := range kys {
+		nam := name(key)
+
+		if nam == "" {
+			continue
+		}
+
+		buf.WriteString(" " + nam + `="` + escape(value(res[key])) + `"`)
+	}
+
+	buf.WriteString("]")
+
+	return buf.String()
+}
+
+// name makes a valid PARAM-NAME
+// 1-32 printable ascii chars except '=', ' ', ']' and '"'
+func name(key string) string {
+	buf := []byte(key)
+
+	for ind, chr := range buf {
+		if chr < 33 || chr > 126 || chr == '=' || chr == ']' || chr == '"' {
+			buf[ind] = '_'
+		}
+	}
+
+	if len(buf) > 32 {
+		buf = buf[:32]
+	}
+
+	return string(buf)
+}
+
+// escape escapes '"', '\' and ']' in a PARAM-VALUE
+func escape(val string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(val)
+}
+
+func value(val any) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+
+	buf, err := json.Marshal(val)
+
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+
+	return string(buf)
+}
+
+// header makes a valid header field
+// printable ascii up to the max length, "-" if empty
+func header(val string, max int) string {
+	buf := []byte(val)
+
+	for ind, chr := range buf {
+		if chr < 33 || chr > 126 {
+			buf[ind] = '_'
+		}
+	}
+
+	if len(buf) > max {
+		buf = buf[:max]
+	}
+
+	if len(buf) == 0 {
+		return "-"
+	}
+
+	return string(buf)
+}
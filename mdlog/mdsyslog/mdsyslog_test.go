@@ -0,0 +1,225 @@
+package mdsyslog_test
+
+import (
+	"bufio"
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdsyslog"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var format = regexp.MustCompile(`^<(\d+)>1 (\S+) host app (\d+) (\S+) (-|\[.*\])(?: (.*))?$`)
+
+func TestUDP(t *testing.T) {
+	lsn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	assert.NoError(t, err)
+
+	defer lsn.Close()
+
+	lgr, err := mdsyslog.New(mdsyslog.Config{
+		Network:  "udp",
+		Address:  lsn.LocalAddr().String(),
+		Level:    mdlog.Info,
+		AppName:  "app",
+		Hostname: "host",
+	})
+
+	assert.NoError(t, err)
+
+	defer lgr.Close()
+
+	ctx := mdctx.WithRequestID(context.Background(), "1234")
+
+	lgr.Debug(ctx, "filtered", nil)
+	lgr.Error(ctx, mderr.New("oh no", nil), map[string]any{
+		"foo":   "bar",
+		"quote": `say "hi"]`,
+		"num":   12,
+		"bad=":  true,
+	})
+
+	buf := make([]byte, 2048)
+
+	assert.NoError(t, lsn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	n, _, err := lsn.ReadFrom(buf)
+
+	assert.NoError(t, err)
+
+	mts := format.FindStringSubmatch(string(buf[:n]))
+
+	assert.NotNil(t, mts, string(buf[:n]))
+	assert.Equal(t, "11", mts[1]) //<< user facility * 8 + err severity
+	assert.Equal(t, "error", mts[4])
+	assert.Equal(t, `[request@32473 id="1234"][md@32473 bad_="true" foo="bar" num="12" quote="say \"hi\"\]"]`, mts[5])
+	assert.Equal(t, "oh no", mts[6])
+
+	lgr.Error(context.Background(), nil, nil)
+
+	ent := mdlog.NewEntry(context.Background(), mdlog.Info, nil, "named", nil)
+
+	ent.Name = "api"
+	ent.Caller = "main.go:12"
+
+	lgr.Log(ent)
+
+	for _, exp := range [][]string{{"-", ""}, {`[log@32473 caller="main.go:12" name="api"]`, "named"}} {
+		assert.NoError(t, lsn.SetReadDeadline(time.Now().Add(time.Second)))
+
+		n, _, err = lsn.ReadFrom(buf)
+
+		assert.NoError(t, err)
+
+		mts = format.FindStringSubmatch(string(buf[:n]))
+
+		assert.NotNil(t, mts, string(buf[:n]))
+		assert.Equal(t, exp[0], mts[5])
+		assert.Equal(t, exp[1], mts[6])
+	}
+}
+
+func TestTCP(t *testing.T) {
+	lsn, err := net.Listen("tcp", "127.0.0.1:0")
+
+	assert.NoError(t, err)
+
+	defer lsn.Close()
+
+	msgs := make(chan string, 2)
+
+	go func() {
+		con, err := lsn.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer con.Close()
+
+		rdr := bufio.NewReader(con)
+
+		for {
+			pfx, err := rdr.ReadString(' ')
+
+			if err != nil {
+				return
+			}
+
+			siz, _ := strconv.Atoi(strings.TrimSpace(pfx))
+			buf := make([]byte, siz)
+
+			_, err = io.ReadFull(rdr, buf)
+
+			if err != nil {
+				return
+			}
+
+			msgs <- string(buf)
+		}
+	}()
+
+	notice := mdlog.Level(35)
+
+	assert.NoError(t, mdlog.RegisterLevel("notice", notice, mdlog.Info))
+
+	fac := 16
+	lgr, err := mdsyslog.New(mdsyslog.Config{
+		Network:  "tcp",
+		Address:  lsn.Addr().String(),
+		Level:    mdlog.Info,
+		Facility: &fac,
+		AppName:  "app",
+		Hostname: "host",
+		Severities: map[mdlog.Level]int{
+			notice: mdsyslog.Notice,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	defer lgr.Close()
+
+	lgr.Info(context.Background(), "hello world", nil)
+	lgr.At(context.Background(), notice, "take note", nil)
+
+	for _, exp := range [][]string{{"134", "info", "-", "hello world"}, {"133", "notice", "-", "take note"}} {
+		select {
+		case msg := <-msgs:
+			mts := format.FindStringSubmatch(msg)
+
+			assert.NotNil(t, mts, msg)
+			assert.Equal(t, exp, []string{mts[1], mts[4], mts[5], mts[6]})
+		case <-time.After(time.Second):
+			assert.Fail(t, "no message received")
+		}
+	}
+}
+
+func TestUnixgram(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "log.sock")
+	lsn, err := net.ListenPacket("unixgram", pth)
+
+	if err != nil {
+		t.Skip("unixgram sockets are not supported")
+	}
+
+	defer lsn.Close()
+	defer os.Remove(pth)
+
+	krn := 0
+	lgr, err := mdsyslog.New(mdsyslog.Config{
+		Network:  "unixgram",
+		Address:  pth,
+		Level:    mdlog.Info,
+		Facility: &krn,
+		AppName:  "app",
+		Hostname: "host",
+	})
+
+	assert.NoError(t, err)
+
+	defer lgr.Close()
+
+	lgr.Warn(context.Background(), "careful", nil)
+
+	buf := make([]byte, 2048)
+
+	assert.NoError(t, lsn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	n, _, err := lsn.ReadFrom(buf)
+
+	assert.NoError(t, err)
+
+	mts := format.FindStringSubmatch(string(buf[:n]))
+
+	assert.NotNil(t, mts, string(buf[:n]))
+	assert.Equal(t, "4", mts[1]) //<< kern facility * 8 + warning severity
+	assert.Equal(t, "careful", mts[6])
+}
+
+func TestNew(t *testing.T) {
+	_, err := mdsyslog.New(mdsyslog.Config{Network: "carrier-pigeon", Address: "coop"})
+
+	assert.Error(t, err)
+
+	_, err = mdsyslog.New(mdsyslog.Config{Network: "udp"})
+
+	assert.Error(t, err)
+
+	fac := 24
+	_, err = mdsyslog.New(mdsyslog.Config{Network: "udp", Address: "127.0.0.1:514", Facility: &fac})
+
+	assert.Error(t, err)
+}
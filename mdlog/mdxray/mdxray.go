@@ -0,0 +1,63 @@
+package mdxray
+
+import (
+	"context"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+)
+
+// Namespace is the xray metadata namespace error entries are recorded under
+const Namespace = "md"
+
+// TraceIDExtractor gets aws xray's trace id from a context
+var TraceIDExtractor = mdlog.TraceIDFunc(xray.TraceID)
+
+// WithAWSXRayTraceID applies aws xray logger middleware
+// the returned Logger will inject aws xray's trace id into the
+// metadata payload with the key
+// if key == "" then "trace-id" is used
+// a key the entry already has is handled per the optional mdlog.CollisionConfig
+func WithAWSXRayTraceID(lgr mdlog.Logger, key string, cls ...mdlog.CollisionConfig) mdlog.Logger {
+	return mdlog.WithTraceID(lgr, TraceIDExtractor, key, cls...)
+}
+
+// WithSegmentErrors applies aws xray segment logger middleware
+// the returned Logger will add fatal, panic and error entries to the
+// xray segment in the context, if there is one
+// the error is added to the segment's cause, its message is added as the
+// "error" annotation, and its error trace and a copy of its metadata are
+// added to the segment's metadata under Namespace
+func WithSegmentErrors(lgr mdlog.Logger) mdlog.Logger {
+	return mdlog.WithErrMod(lgr, func(ctx context.Context, err error, md map[string]any, f mdlog.ErrFunc) {
+		annotate(ctx, err, md)
+
+		f(ctx, err, md)
+	})
+}
+
+func annotate(ctx context.Context, err error, md map[string]any) {
+	if ctx == nil || err == nil {
+		return
+	}
+
+	seg := xray.GetSegment(ctx)
+
+	if seg == nil {
+		return
+	}
+
+	_ = seg.AddError(err)
+	_ = seg.AddAnnotation("error", mderr.Message(err))
+	_ = seg.AddMetadataToNamespace(Namespace, "error-trace", mderr.Stack(err))
+
+	if len(md) > 0 {
+		cpy := make(map[string]any, len(md))
+
+		for key, val := range md {
+			cpy[key] = val
+		}
+
+		_ = seg.AddMetadataToNamespace(Namespace, "metadata", cpy)
+	}
+}
@@ -0,0 +1,69 @@
+package mdxray_test
+
+import (
+	"context"
+	"github.com/aws/aws-xray-sdk-go/strategy/sampling"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdxray"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testLogger struct {
+	mdlog.Logger
+	md map[string]any
+}
+
+func (t *testLogger) Error(_ context.Context, _ error, md map[string]any) {
+	t.md = md
+}
+
+func segment(t *testing.T) (context.Context, *xray.Segment) {
+	sst, err := sampling.NewLocalizedStrategyFromJSONBytes([]byte(`{"version":2,"default":{"fixed_target":0,"rate":1}}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := xray.ContextWithConfig(context.Background(), xray.Config{
+		SamplingStrategy: sst,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return xray.BeginSegment(ctx, "test")
+}
+
+func TestWithAWSXRayTraceID(t *testing.T) {
+	ctx, seg := segment(t)
+	tst := &testLogger{}
+	lgr := mdxray.WithAWSXRayTraceID(tst, "")
+
+	lgr.Error(ctx, mderr.New("error", nil), nil)
+
+	assert.Equal(t, seg.TraceID, tst.md["trace-id"])
+}
+
+func TestWithSegmentErrors(t *testing.T) {
+	ctx, seg := segment(t)
+	tst := &testLogger{}
+	lgr := mdxray.WithSegmentErrors(tst)
+
+	cmd := map[string]any{
+		"foo": "bar",
+	}
+
+	lgr.Error(ctx, mderr.Wrap(mderr.New("root error", nil), "surface error", nil), cmd)
+
+	cmd["foo"] = "changed"
+
+	assert.True(t, seg.Fault)
+	assert.Len(t, seg.GetCause().Exceptions, 1)
+	assert.Equal(t, "surface error", seg.Annotations["error"])
+	assert.Equal(t, map[string]any{"foo": "bar"}, seg.Metadata[mdxray.Namespace]["metadata"])
+	assert.Equal(t, map[string]any{"foo": "changed"}, tst.md)
+}
@@ -0,0 +1,26 @@
+package mdyaml
+
+import (
+	"bytes"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFromYAML decodes the logger config from yaml
+// (separate package so the yaml module is only pulled in if you use it)
+// unset fields use the mdlog.DefaultConfig values
+func ConfigFromYAML(buf []byte) (mdlog.Config, error) {
+	cfg := mdlog.DefaultConfig()
+	dec := yaml.NewDecoder(bytes.NewReader(buf))
+
+	dec.KnownFields(true)
+
+	err := dec.Decode(&cfg)
+
+	if err != nil {
+		return cfg, mderr.Wrap(err, "failed to decode yaml log config", nil)
+	}
+
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,49 @@
+package mdyaml_test
+
+import (
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"github.com/chaseisabelle/md/mdlog/mdyaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConfigFromYAML(t *testing.T) {
+	cfg, err := mdyaml.ConfigFromYAML([]byte("backend: zap\nlevel: error\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdlog.Config{Backend: "zap", Level: mdlog.Error}, cfg)
+
+	_, err = mdyaml.ConfigFromYAML([]byte("level: loud\n"))
+
+	assert.Error(t, err)
+
+	_, err = mdyaml.ConfigFromYAML([]byte("lvl: debug\n"))
+
+	assert.Error(t, err)
+
+	cfg, err = mdyaml.ConfigFromYAML([]byte("file:\n  path: app.log\n  interval: 1h\n  max-backups: 3\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, mdfile.Duration(time.Hour), cfg.File.Interval)
+	assert.Equal(t, 3, cfg.File.MaxBackups)
+
+	cfg, err = mdyaml.ConfigFromYAML([]byte(`
+backend: zero
+filter:
+  - action: keep
+    levels: [error]
+    error-root: timeout
+  - levels: [info, error]
+    message-regex: ^(health|timeout)
+    metadata:
+      path: /healthz
+`))
+
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Filter, 2)
+	assert.Equal(t, mdlog.Keep, cfg.Filter[0].Action)
+	assert.Equal(t, []mdlog.Level{mdlog.Info, mdlog.Error}, cfg.Filter[1].Levels)
+	assert.Equal(t, map[string]string{"path": "/healthz"}, cfg.Filter[1].Metadata)
+}
@@ -2,65 +2,55 @@ package mdzap
 
 import (
 	"context"
+	"github.com/chaseisabelle/md/mderr"
 	"github.com/chaseisabelle/md/mdlog"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"os"
+	"io"
 )
 
 type Zap struct {
 	logger *zap.Logger
+	level  mdlog.Level
+	closer io.Closer
+	caller bool
+}
+
+func init() {
+	mdlog.Register("zap", func(cfg mdlog.Config) (mdlog.Logger, error) {
+		return New(cfg)
+	})
 }
 
 func New(cfg mdlog.Config) (*Zap, error) {
 	cll := cfg.Level
 
+	if !cll.Valid() {
+		return nil, mderr.New("invalid log level", map[string]any{
+			"level": int(cll),
+		})
+	}
+
+	// levels are filtered by mdlog so user-defined levels filter like the built-in ones
+	// zap's levels only pick stdout or stderr
 	ile := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		var lev mdlog.Level
-
-		switch lvl {
-		case zapcore.FatalLevel:
-			fallthrough
-		case zapcore.ErrorLevel:
-			return false
-		case zapcore.WarnLevel:
-			lev = mdlog.Warn
-		case zapcore.InfoLevel:
-			lev = mdlog.Info
-		case zapcore.DebugLevel:
-			lev = mdlog.Debug
-		default:
-			lev = mdlog.Info
-		}
-
-		return lev >= cll
+		return lvl < zapcore.ErrorLevel
 	})
 
 	ele := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		var lev mdlog.Level
-
-		switch lvl {
-		case zapcore.FatalLevel:
-			lev = mdlog.Fatal
-		case zapcore.ErrorLevel:
-			lev = mdlog.Error
-		case zapcore.WarnLevel:
-			fallthrough
-		case zapcore.InfoLevel:
-			fallthrough
-		case zapcore.DebugLevel:
-			fallthrough
-		default:
-			return true
-		}
-
-		return lev >= cll
+		return lvl >= zapcore.ErrorLevel
 	})
 
-	sos := zapcore.Lock(os.Stdout)
-	ses := zapcore.Lock(os.Stderr)
-	zoc := zap.NewProductionEncoderConfig()
-	zec := zap.NewProductionEncoderConfig()
+	sow, sew, cls, err := cfg.Outputs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sos := zapcore.Lock(zapcore.AddSync(sow))
+	ses := zapcore.Lock(zapcore.AddSync(sew))
+	zoc := encoderConfig()
+	zec := encoderConfig()
 	zoe := zapcore.NewJSONEncoder(zoc)
 	zee := zapcore.NewJSONEncoder(zec)
 	ozc := zapcore.NewCore(zoe, sos, ile)
@@ -70,42 +60,148 @@ func New(cfg mdlog.Config) (*Zap, error) {
 
 	return &Zap{
 		logger: lgr,
+		level:  cll,
+		closer: cls,
+		caller: cfg.Caller,
 	}, nil
 }
 
+// Close flushes buffered entries and closes the log file, if there is one
+func (z *Zap) Close() error {
+	_ = z.logger.Sync()
+
+	return z.closer.Close()
+}
+
 func (z *Zap) Fatal(ctx context.Context, err error, md map[string]any) {
-	z.logger.Fatal(err.Error(), metadata(md))
+	z.write(mdlog.NewEntry(ctx, mdlog.Fatal, err, "", md))
+}
+
+func (z *Zap) Panic(ctx context.Context, err error, md map[string]any) {
+	z.write(mdlog.NewEntry(ctx, mdlog.Panic, err, "", md))
+
+	panic(err)
 }
 
 func (z *Zap) Error(ctx context.Context, err error, md map[string]any) {
-	z.logger.Error(err.Error(), metadata(md))
+	z.write(mdlog.NewEntry(ctx, mdlog.Error, err, "", md))
 }
 
 func (z *Zap) Warn(ctx context.Context, msg string, md map[string]any) {
-	z.logger.Warn(msg, metadata(md))
+	z.write(mdlog.NewEntry(ctx, mdlog.Warn, nil, msg, md))
 }
 
 func (z *Zap) Info(ctx context.Context, msg string, md map[string]any) {
-	z.logger.Info(msg, metadata(md))
+	z.write(mdlog.NewEntry(ctx, mdlog.Info, nil, msg, md))
 }
 
 func (z *Zap) Debug(ctx context.Context, msg string, md map[string]any) {
-	z.logger.Debug(msg, metadata(md))
+	z.write(mdlog.NewEntry(ctx, mdlog.Debug, nil, msg, md))
 }
 
-func metadata(md map[string]any) zapcore.Field {
-	if md == nil {
-		return zap.Field{}
+func (z *Zap) Trace(ctx context.Context, msg string, md map[string]any) {
+	z.write(mdlog.NewEntry(ctx, mdlog.Trace, nil, msg, md))
+}
+
+// Enabled checks if entries at the level get written
+func (z *Zap) Enabled(ctx context.Context, lvl mdlog.Level) bool {
+	return z.level.AllowsContext(ctx, lvl)
+}
+
+// At writes an entry at any level
+// user-defined levels are written as their base level
+func (z *Zap) At(ctx context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+	z.Log(mdlog.NewEntry(ctx, lvl, nil, msg, md))
+}
+
+// Log writes a whole entry
+// the entry's name is written as the "logger" field
+// fatal entries exit and panic entries panic, like the Logger methods
+func (z *Zap) Log(ent mdlog.Entry) {
+	z.write(ent)
+
+	if ent.Level.Base() != mdlog.Panic {
+		return
 	}
 
-	fds := make([]zapcore.Field, len(md))
-	ind := 0
+	if ent.Error != nil {
+		panic(ent.Error)
+	}
 
-	for k, v := range md {
-		fds[ind] = zap.Any(k, v)
+	panic(ent.Message)
+}
+
+func (z *Zap) write(ent mdlog.Entry) {
+	if !z.level.AllowsContext(ent.Context, ent.Level) {
+		return
+	}
 
-		ind++
+	msg := ent.Message
+
+	if ent.Error != nil {
+		msg = ent.Error.Error()
+	}
+
+	ce := z.logger.Check(level(ent.Level.Base()), msg)
+
+	if ce == nil {
+		return
+	}
+
+	if !ent.Time.IsZero() {
+		ce.Time = ent.Time
+	}
+
+	ce.LoggerName = ent.Name
+
+	clr := ent.Caller
+
+	if clr == "" && z.caller {
+		clr = mdlog.Caller(ent.Context)
+	}
+
+	cf := zap.Skip()
+
+	if clr != "" {
+		cf = zap.String("caller", clr)
+	}
+
+	ce.Write(zap.String("level", ent.Level.String()), cf, metadata(ent.Metadata))
+}
+
+// encoderConfig is zap's production config without the level key
+// the level is added as a field so user-defined levels keep their names
+func encoderConfig() zapcore.EncoderConfig {
+	zec := zap.NewProductionEncoderConfig()
+
+	zec.LevelKey = zapcore.OmitKey
+
+	return zec
+}
+
+// level gets the zap level for a built-in level
+// panic is written as dpanic so the panic happens with the error
+func level(lvl mdlog.Level) zapcore.Level {
+	switch lvl {
+	case mdlog.Fatal:
+		return zapcore.FatalLevel
+	case mdlog.Panic:
+		return zapcore.DPanicLevel
+	case mdlog.Error:
+		return zapcore.ErrorLevel
+	case mdlog.Warn:
+		return zapcore.WarnLevel
+	case mdlog.Info:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+func metadata(md map[string]any) zapcore.Field {
+	if md == nil {
+		return zap.Skip()
 	}
 
-	return zap.Any("metadata", fds)
+	return zap.Any("metadata", md)
 }
@@ -2,70 +2,200 @@ package mdzero
 
 import (
 	"context"
+	"github.com/chaseisabelle/md/mderr"
 	"github.com/chaseisabelle/md/mdlog"
-	"github.com/chaseisabelle/mderr"
 	"github.com/rs/zerolog"
+	"io"
 	"os"
+	"time"
 )
 
 type Zero struct {
 	stdout zerolog.Logger
 	stderr zerolog.Logger
+	level  mdlog.Level
+	closer io.Closer
+	caller bool
+}
+
+func init() {
+	mdlog.Register("zero", func(cfg mdlog.Config) (mdlog.Logger, error) {
+		return New(cfg)
+	})
 }
 
 func New(cfg mdlog.Config) (*Zero, error) {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
-	sol := zerolog.New(os.Stdout).With().Timestamp().Logger()
-	sel := zerolog.New(os.Stderr).With().Timestamp().Logger()
 	lvl := cfg.Level
 
-	var zll zerolog.Level
-
-	switch lvl {
-	case mdlog.Fatal:
-		zll = zerolog.FatalLevel
-	case mdlog.Error:
-		zll = zerolog.ErrorLevel
-	case mdlog.Warn:
-		zll = zerolog.WarnLevel
-	case mdlog.Info:
-		zll = zerolog.InfoLevel
-	case mdlog.Debug:
-		zll = zerolog.DebugLevel
-	default:
+	if !lvl.Valid() {
 		return nil, mderr.New("invalid log level", map[string]any{
-			"level": lvl,
+			"level": int(lvl),
 		})
 	}
 
-	sol = sol.Level(zll)
-	sel = sel.Level(zll)
+	sow, sew, cls, err := cfg.Outputs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the time is added by event so entries keep the time they were logged at
+	sol := zerolog.New(sow)
+	sel := zerolog.New(sew)
+
+	// levels are filtered by mdlog so user-defined levels filter like the built-in ones
+	sol = sol.Level(zerolog.TraceLevel)
+	sel = sel.Level(zerolog.TraceLevel)
 
 	return &Zero{
 		stdout: sol,
 		stderr: sel,
+		level:  lvl,
+		closer: cls,
+		caller: cfg.Caller,
 	}, nil
 }
 
+// Close closes the log file, if there is one
+func (z *Zero) Close() error {
+	return z.closer.Close()
+}
+
 func (z *Zero) Fatal(ctx context.Context, err error, md map[string]any) {
-	z.stderr.Fatal().Err(err).Fields(metadata(md)).Msg("")
+	z.write(mdlog.NewEntry(ctx, mdlog.Fatal, err, "", md))
+
+	os.Exit(1)
+}
+
+func (z *Zero) Panic(ctx context.Context, err error, md map[string]any) {
+	z.write(mdlog.NewEntry(ctx, mdlog.Panic, err, "", md))
+
+	panic(err)
 }
 
 func (z *Zero) Error(ctx context.Context, err error, md map[string]any) {
-	z.stderr.Error().Err(err).Fields(metadata(md)).Msg("")
+	z.write(mdlog.NewEntry(ctx, mdlog.Error, err, "", md))
 }
 
 func (z *Zero) Warn(ctx context.Context, msg string, md map[string]any) {
-	z.stdout.Warn().Fields(metadata(md)).Msg(msg)
+	z.write(mdlog.NewEntry(ctx, mdlog.Warn, nil, msg, md))
 }
 
 func (z *Zero) Info(ctx context.Context, msg string, md map[string]any) {
-	z.stdout.Info().Fields(metadata(md)).Msg(msg)
+	z.write(mdlog.NewEntry(ctx, mdlog.Info, nil, msg, md))
 }
 
 func (z *Zero) Debug(ctx context.Context, msg string, md map[string]any) {
-	z.stdout.Debug().Fields(metadata(md)).Msg(msg)
+	z.write(mdlog.NewEntry(ctx, mdlog.Debug, nil, msg, md))
+}
+
+func (z *Zero) Trace(ctx context.Context, msg string, md map[string]any) {
+	z.write(mdlog.NewEntry(ctx, mdlog.Trace, nil, msg, md))
+}
+
+// Enabled checks if entries at the level get written
+func (z *Zero) Enabled(ctx context.Context, lvl mdlog.Level) bool {
+	return z.level.AllowsContext(ctx, lvl)
+}
+
+// At writes an entry at any level
+// user-defined levels are written as their base level
+func (z *Zero) At(ctx context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+	z.Log(mdlog.NewEntry(ctx, lvl, nil, msg, md))
+}
+
+// Log writes a whole entry
+// the entry's name is written as the "logger" field
+// fatal entries exit and panic entries panic, like the Logger methods
+func (z *Zero) Log(ent mdlog.Entry) {
+	z.write(ent)
+
+	switch ent.Level.Base() {
+	case mdlog.Fatal:
+		os.Exit(1)
+	case mdlog.Panic:
+		if ent.Error != nil {
+			panic(ent.Error)
+		}
+
+		panic(ent.Message)
+	}
+}
+
+func (z *Zero) write(ent mdlog.Entry) {
+	evt := z.event(ent)
+
+	if ent.Error != nil {
+		evt = evt.Err(ent.Error)
+	}
+
+	evt.Fields(metadata(ent.Metadata)).Msg(ent.Message)
+}
+
+// event starts an entry, nil if the level is filtered out
+func (z *Zero) event(ent mdlog.Entry) *zerolog.Event {
+	if !z.level.AllowsContext(ent.Context, ent.Level) {
+		return nil
+	}
+
+	tim := ent.Time
+
+	if tim.IsZero() {
+		tim = time.Now()
+	}
+
+	evt := z.start(ent.Level).Time(zerolog.TimestampFieldName, tim)
+
+	if ent.Name != "" {
+		evt = evt.Str("logger", ent.Name)
+	}
+
+	clr := ent.Caller
+
+	if clr == "" && z.caller {
+		clr = mdlog.Caller(ent.Context)
+	}
+
+	if clr != "" {
+		evt = evt.Str(zerolog.CallerFieldName, clr)
+	}
+
+	return evt
+}
+
+// start starts an entry at the level
+// fatal, panic and error entries go to stderr, the rest go to stdout
+func (z *Zero) start(lvl mdlog.Level) *zerolog.Event {
+	bas := lvl.Base()
+	lgr := &z.stdout
+
+	if mdlog.Error.Allows(bas) {
+		lgr = &z.stderr
+	}
+
+	// zerolog's global level drops trace entries by default
+	// and it has no user-defined levels, so these are written without
+	// a zerolog level and the level name is added as a field
+	if lvl != bas || bas == mdlog.Trace {
+		return lgr.Log().Str(zerolog.LevelFieldName, lvl.String())
+	}
+
+	switch bas {
+	case mdlog.Fatal:
+		return lgr.WithLevel(zerolog.FatalLevel)
+	case mdlog.Panic:
+		return lgr.WithLevel(zerolog.PanicLevel)
+	case mdlog.Error:
+		return lgr.WithLevel(zerolog.ErrorLevel)
+	case mdlog.Warn:
+		return lgr.WithLevel(zerolog.WarnLevel)
+	case mdlog.Info:
+		return lgr.WithLevel(zerolog.InfoLevel)
+	default:
+		return lgr.WithLevel(zerolog.DebugLevel)
+	}
 }
 
 func metadata(md map[string]any) map[string]any {
@@ -0,0 +1,229 @@
+package mdzero_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/chaseisabelle/md/mdlog/mdfile"
+	"github.com/chaseisabelle/md/mdlog/mdzero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func entries(t *testing.T, pth string) []map[string]any {
+	fil, err := os.Open(pth)
+
+	assert.NoError(t, err)
+
+	defer fil.Close()
+
+	var ens []map[string]any
+
+	scn := bufio.NewScanner(fil)
+
+	for scn.Scan() {
+		var ent map[string]any
+
+		assert.NoError(t, json.Unmarshal(scn.Bytes(), &ent))
+
+		ens = append(ens, ent)
+	}
+
+	return ens
+}
+
+func TestZero(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "app.log")
+	lgr, err := mdzero.New(mdlog.Config{
+		Level: mdlog.Info,
+		File: mdfile.Config{
+			Path: pth,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	lgr.Error(ctx, mderr.New("error", nil), map[string]any{"foo": "bar"})
+	lgr.Warn(ctx, "warn", nil)
+	lgr.Info(ctx, "info", nil)
+	lgr.Debug(ctx, "debug", nil)
+	lgr.Trace(ctx, "trace", nil)
+
+	assert.Panics(t, func() {
+		lgr.Panic(ctx, mderr.New("panic", nil), nil)
+	})
+
+	assert.True(t, lgr.Enabled(ctx, mdlog.Info))
+	assert.False(t, lgr.Enabled(ctx, mdlog.Debug))
+	assert.NoError(t, lgr.Close())
+
+	ens := entries(t, pth)
+
+	assert.Len(t, ens, 4)
+	assert.Equal(t, "error", ens[0]["level"])
+	assert.Equal(t, "error", ens[0]["error"])
+	assert.Equal(t, map[string]any{"foo": "bar"}, ens[0]["metadata"])
+	assert.Equal(t, "warn", ens[1]["level"])
+	assert.Equal(t, "info", ens[2]["level"])
+	assert.Equal(t, "panic", ens[3]["level"])
+}
+
+func TestZeroLevelContext(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "app.log")
+	lgr, err := mdzero.New(mdlog.Config{
+		Level: mdlog.Info,
+		File: mdfile.Config{
+			Path: pth,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	ctx := mdlog.LevelContext(context.Background(), mdlog.Debug)
+
+	lgr.Debug(context.Background(), "skipped", nil)
+	lgr.Debug(ctx, "debug", nil)
+	lgr.Trace(ctx, "trace", nil)
+
+	assert.True(t, lgr.Enabled(ctx, mdlog.Debug))
+	assert.NoError(t, lgr.Close())
+
+	ens := entries(t, pth)
+
+	assert.Len(t, ens, 1)
+	assert.Equal(t, "debug", ens[0]["message"])
+}
+
+func BenchmarkMods(b *testing.B) {
+	lgr, err := mdzero.New(mdlog.Config{
+		Level: mdlog.Info,
+		File: mdfile.Config{
+			Path: filepath.Join(b.TempDir(), "app.log"),
+		},
+	})
+
+	assert.NoError(b, err)
+
+	defer lgr.Close()
+
+	pmd := map[string]any{"env": "bench", "app": "md"}
+	tie := mdlog.TraceIDFunc(func(context.Context) string {
+		return "tid"
+	})
+
+	var stk mdlog.Logger = lgr
+
+	stk = mdlog.WithPersistedMetadata(stk, pmd)
+	stk = mdlog.WithRequestID(stk, "")
+	stk = mdlog.WithTraceID(stk, tie, "")
+	stk = mdlog.WithErrorTrace(stk, "")
+
+	pip := mdlog.NewPipeline().
+		PersistedMetadata(pmd).
+		RequestID("").
+		TraceID(tie, "").
+		ErrorTrace("").
+		Build(lgr)
+
+	ctx := mdctx.WithRequestID(context.Background(), "rid")
+
+	for nam, lgr := range map[string]mdlog.Logger{"stacked": stk, "pipeline": pip} {
+		b.Run(nam+"/info", func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				lgr.Info(ctx, "info", map[string]any{"foo": "bar"})
+			}
+		})
+
+		b.Run(nam+"/debug", func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				lgr.Debug(ctx, "debug", map[string]any{"foo": "bar"})
+			}
+		})
+	}
+}
+
+func TestCaller(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "app.log")
+	bck, err := mdzero.New(mdlog.Config{
+		Level:  mdlog.Info,
+		Caller: true,
+		File: mdfile.Config{
+			Path: pth,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	var lgr mdlog.Logger = bck
+
+	lgr = mdlog.WithBuffering(lgr)
+	lgr = mdlog.WithMods(lgr, nil, func(ctx context.Context, msg string, md map[string]any, f mdlog.MsgFunc) {
+		f(ctx, msg, md)
+	})
+	lgr = mdlog.WithRequestID(lgr, "")
+	lgr = mdlog.WithErrorTrace(lgr, "")
+
+	ctx, buf := mdlog.BufferContext(context.Background(), 0)
+
+	_, _, lin, _ := runtime.Caller(0)
+
+	lgr.Info(context.Background(), "direct", nil)
+	mdlog.At(lgr, context.Background(), mdlog.Warn, "at", nil)
+	lgr.Info(ctx, "buffered", nil)
+	buf.Flush()
+
+	assert.NoError(t, bck.Close())
+
+	ens := entries(t, pth)
+
+	assert.Len(t, ens, 3)
+
+	for i, off := range []int{2, 3, 4} {
+		assert.Equal(t, "mdzero/mdzero_test.go:"+strconv.Itoa(lin+off), ens[i]["caller"])
+	}
+}
+
+func TestZeroLog(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "app.log")
+	lgr, err := mdzero.New(mdlog.Config{
+		Level: mdlog.Info,
+		File: mdfile.Config{
+			Path: pth,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	tim := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mdlog.Log(mdlog.WithName(lgr, "api"), mdlog.Entry{
+		Context: context.Background(),
+		Level:   mdlog.Info,
+		Time:    tim,
+		Message: "info",
+		Caller:  "app/main.go:1",
+	})
+
+	assert.NoError(t, lgr.Close())
+
+	ens := entries(t, pth)
+
+	assert.Len(t, ens, 1)
+	assert.Equal(t, float64(tim.Unix()), ens[0]["time"])
+	assert.Equal(t, "api", ens[0]["logger"])
+	assert.Equal(t, "app/main.go:1", ens[0]["caller"])
+}
@@ -0,0 +1,189 @@
+package mdlog
+
+import (
+	"context"
+	"fmt"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mderr"
+	"sync"
+)
+
+// Overflow is the value counted once a label has MaxValues distinct values
+const Overflow = "other"
+
+// MetricsConfig is the Metrics config
+type MetricsConfig struct {
+	// Labels are the metadata keys entries are also counted by
+	// lazy values aren't counted, so they're only computed if the entry is written
+	Labels []string
+	// MaxValues is the max distinct values counted for each label and for errors,
+	// the rest are counted as Overflow, defaults to 100
+	MaxValues int
+	// CodeKey is the error metadata key errors are counted by,
+	// errors without a code are counted by their root message, defaults to "code"
+	CodeKey string
+}
+
+// Metrics counts log entries by level, error and metadata labels
+// see WithMetrics
+type Metrics struct {
+	config MetricsConfig
+	mutex  sync.Mutex
+	levels map[string]uint64
+	errors map[string]uint64
+	labels map[string]map[string]uint64
+}
+
+// MetricsSnapshot is a copy of the counts
+type MetricsSnapshot struct {
+	// Levels are the entry counts by level name
+	Levels map[string]uint64
+	// Errors are the fatal+panic+error entry counts by error code or root message
+	Errors map[string]uint64
+	// Labels are the entry counts by label then value
+	Labels map[string]map[string]uint64
+}
+
+// NewMetrics creates a Metrics
+func NewMetrics(cfg MetricsConfig) *Metrics {
+	if cfg.MaxValues <= 0 {
+		cfg.MaxValues = 100
+	}
+
+	if cfg.CodeKey == "" {
+		cfg.CodeKey = "code"
+	}
+
+	lbs := make(map[string]map[string]uint64, len(cfg.Labels))
+
+	for _, lbl := range cfg.Labels {
+		lbs[lbl] = map[string]uint64{}
+	}
+
+	return &Metrics{
+		config: cfg,
+		levels: map[string]uint64{},
+		errors: map[string]uint64{},
+		labels: lbs,
+	}
+}
+
+// Count counts an entry
+// err is nil for message entries
+func (m *Metrics) Count(lvl Level, err error, mmd map[string]any) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.levels[lvl.String()]++
+
+	if err != nil {
+		m.increment(m.errors, m.code(err))
+	}
+
+	for lbl, vls := range m.labels {
+		val, ok := mmd[lbl]
+
+		if _, lzy := val.(*md.LazyValue); ok && !lzy {
+			m.increment(vls, fmt.Sprint(val))
+		}
+	}
+}
+
+// Snapshot gets a copy of the counts
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snp := MetricsSnapshot{
+		Levels: make(map[string]uint64, len(m.levels)),
+		Errors: make(map[string]uint64, len(m.errors)),
+		Labels: make(map[string]map[string]uint64, len(m.labels)),
+	}
+
+	for key, val := range m.levels {
+		snp.Levels[key] = val
+	}
+
+	for key, val := range m.errors {
+		snp.Errors[key] = val
+	}
+
+	for lbl, vls := range m.labels {
+		snp.Labels[lbl] = make(map[string]uint64, len(vls))
+
+		for key, val := range vls {
+			snp.Labels[lbl][key] = val
+		}
+	}
+
+	return snp
+}
+
+// increment counts a value, or Overflow if there are too many values
+// the caller must hold the mutex
+func (m *Metrics) increment(cnt map[string]uint64, val string) {
+	_, ok := cnt[val]
+
+	if !ok && len(cnt) >= m.config.MaxValues {
+		val = Overflow
+	}
+
+	cnt[val]++
+}
+
+// code gets the first code in the error stack, or the root message
+func (m *Metrics) code(err error) string {
+	for _, cur := range mderr.Array(err) {
+		val, ok := mderr.Metadata(cur)[m.config.CodeKey]
+
+		if _, lzy := val.(*md.LazyValue); ok && !lzy {
+			return fmt.Sprint(val)
+		}
+	}
+
+	return mderr.Message(mderr.Root(err))
+}
+
+// WithMetrics applies metrics logger middleware
+// the returned Logger counts the entries the Logger writes in the Metrics,
+// entries at levels it doesn't write aren't counted, see Enabled
+// apply it first, on top of the backend, to count by metadata added by the other mods
+func WithMetrics(lgr Logger, mtr *Metrics) Logger {
+	em := func(lvl Level) ErrMod {
+		return func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+			if Enabled(lgr, ctx, lvl) {
+				mtr.Count(lvl, err, md)
+			}
+
+			f(ctx, err, md)
+		}
+	}
+
+	mm := func(lvl Level) MsgMod {
+		return func(ctx context.Context, msg string, md map[string]any, f MsgFunc) {
+			if Enabled(lgr, ctx, lvl) {
+				mtr.Count(lvl, nil, md)
+			}
+
+			f(ctx, msg, md)
+		}
+	}
+
+	return &Modder{
+		logger: lgr,
+		fatal:  em(Fatal),
+		panic:  em(Panic),
+		error:  em(Error),
+		warn:   mm(Warn),
+		info:   mm(Info),
+		debug:  mm(Debug),
+		trace:  mm(Trace),
+		level: func(ctx context.Context, lvl Level, msg string, md map[string]any, f LvlFunc) {
+			if Enabled(lgr, ctx, lvl) {
+				mtr.Count(lvl, nil, md)
+			}
+
+			f(ctx, lvl, msg, md)
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package mdlog_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithMetrics(t *testing.T) {
+	nef := func(context.Context, error, map[string]any) {}
+	nmf := func(context.Context, string, map[string]any) {}
+	mtr := mdlog.NewMetrics(mdlog.MetricsConfig{
+		Labels:    []string{"env", "user"},
+		MaxValues: 2,
+	})
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		ErrorFunc: nef,
+		InfoFunc:  nmf,
+		DebugFunc: nmf,
+	}
+
+	lgr = mdlog.WithMetrics(lgr, mtr)
+	lgr = mdlog.WithPersistedMetadata(lgr, map[string]any{
+		"env": "prod",
+	})
+
+	ctx := context.Background()
+	root := fmt.Errorf("connection refused")
+
+	lgr.Error(ctx, mderr.Wrap(root, "failed to query", nil), nil)
+	lgr.Error(ctx, mderr.Wrap(root, "failed to insert", nil), nil)
+	lgr.Error(ctx, mderr.Wrap(mderr.New("not found", map[string]any{"code": 404}), "failed to get user", nil), nil)
+
+	for i := 0; i < 3; i++ {
+		lgr.Info(ctx, "info", map[string]any{
+			"user": i,
+		})
+	}
+
+	lgr.Debug(ctx, "debug", nil)
+
+	snp := mtr.Snapshot()
+
+	assert.Equal(t, map[string]uint64{"error": 3, "info": 3, "debug": 1}, snp.Levels)
+	assert.Equal(t, map[string]uint64{"connection refused": 2, "404": 1}, snp.Errors)
+	assert.Equal(t, map[string]uint64{"prod": 7}, snp.Labels["env"])
+	assert.Equal(t, map[string]uint64{"0": 1, "1": 1, mdlog.Overflow: 1}, snp.Labels["user"])
+}
+
+func TestWithMetricsEnabled(t *testing.T) {
+	var cnt int
+
+	nmf := func(context.Context, string, map[string]any) {}
+	mtr := mdlog.NewMetrics(mdlog.MetricsConfig{
+		Labels: []string{"user"},
+	})
+
+	lgr := mdlog.WithMetrics(&TestLogger{
+		InfoFunc:  nmf,
+		DebugFunc: nmf,
+		EnabledFunc: func(_ context.Context, lvl mdlog.Level) bool {
+			return mdlog.Info.Allows(lvl)
+		},
+	}, mtr)
+
+	lzy := md.Lazy(func() any {
+		cnt++
+
+		return "lazy"
+	})
+
+	lgr.Info(nil, "info", map[string]any{"user": lzy})
+	lgr.Debug(nil, "debug", nil)
+
+	snp := mtr.Snapshot()
+
+	assert.Equal(t, map[string]uint64{"info": 1}, snp.Levels)
+	assert.Empty(t, snp.Labels["user"])
+	assert.Equal(t, 0, cnt)
+}
@@ -1,15 +1,22 @@
 package mdlog
 
-import "context"
+import (
+	"context"
+	"github.com/chaseisabelle/md/mderr"
+)
 
 // Modder is a Logger middleware
 type Modder struct {
 	logger Logger
 	fatal  ErrMod
+	panic  ErrMod
 	error  ErrMod
 	warn   MsgMod
 	info   MsgMod
 	debug  MsgMod
+	trace  MsgMod
+	level  LvlMod
+	entry  EntryMod
 }
 
 // ErrMod is a func to modify an error entry
@@ -18,11 +25,27 @@ type ErrMod func(context.Context, error, map[string]any, ErrFunc)
 // MsgMod is a func to modify a message entry
 type MsgMod func(context.Context, string, map[string]any, MsgFunc)
 
+// LvlMod is a func to modify a user-defined level entry
+type LvlMod func(context.Context, Level, string, map[string]any, LvlFunc)
+
+// RouteMod is a func to modify any entry, including its level
+// err is nil for message entries and msg is "" for error entries
+// the mod writes the entry to the Logger at any level, see Route,
+// or doesn't write it at all
+type RouteMod func(context.Context, Level, error, string, map[string]any, Logger)
+
 // Fatal mod a fatal error entry
 func (m *Modder) Fatal(ctx context.Context, err error, md map[string]any) {
 	m.fatal(ctx, err, md, m.logger.Fatal)
 }
 
+// Panic mod a panic error entry
+func (m *Modder) Panic(ctx context.Context, err error, md map[string]any) {
+	m.panic(ctx, err, md, func(ctx context.Context, err error, md map[string]any) {
+		LogPanic(m.logger, ctx, err, md)
+	})
+}
+
 // Error mod an error entry
 func (m *Modder) Error(ctx context.Context, err error, md map[string]any) {
 	m.error(ctx, err, md, m.logger.Error)
@@ -43,7 +66,89 @@ func (m *Modder) Debug(ctx context.Context, msg string, md map[string]any) {
 	m.debug(ctx, msg, md, m.logger.Debug)
 }
 
+// Trace mod trace entry
+func (m *Modder) Trace(ctx context.Context, msg string, md map[string]any) {
+	m.trace(ctx, msg, md, func(ctx context.Context, msg string, md map[string]any) {
+		LogTrace(m.logger, ctx, msg, md)
+	})
+}
+
+// At mod an entry at any level
+// built-in levels go to the mod for that level
+// user-defined levels go to the level mod
+func (m *Modder) At(ctx context.Context, lvl Level, msg string, md map[string]any) {
+	if lvl.Builtin() {
+		At(m, ctx, lvl, msg, md)
+
+		return
+	}
+
+	m.level(ctx, lvl, msg, md, func(ctx context.Context, lvl Level, msg string, md map[string]any) {
+		At(m.logger, ctx, lvl, msg, md)
+	})
+}
+
+// Log mods a whole entry
+// the entry goes to the entry mod, if there is one, otherwise to the mod for
+// its level, and the entry's other fields, like the time and name, are kept
+func (m *Modder) Log(ent Entry) {
+	nxt := func(ent Entry) {
+		Log(m.logger, ent)
+	}
+
+	if m.entry != nil {
+		m.entry(ent, nxt)
+
+		return
+	}
+
+	ef := func(ctx context.Context, err error, md map[string]any) {
+		ent.Context, ent.Error, ent.Metadata = ctx, err, md
+
+		nxt(ent)
+	}
+
+	mf := func(ctx context.Context, msg string, md map[string]any) {
+		ent.Context, ent.Message, ent.Metadata = ctx, msg, md
+
+		nxt(ent)
+	}
+
+	if ent.Level.Builtin() && Error.Allows(ent.Level) && ent.Error == nil {
+		ent.Error = mderr.New(ent.Message, nil)
+	}
+
+	switch ent.Level {
+	case Fatal:
+		m.fatal(ent.Context, ent.Error, ent.Metadata, ef)
+	case Panic:
+		m.panic(ent.Context, ent.Error, ent.Metadata, ef)
+	case Error:
+		m.error(ent.Context, ent.Error, ent.Metadata, ef)
+	case Warn:
+		m.warn(ent.Context, ent.Message, ent.Metadata, mf)
+	case Info:
+		m.info(ent.Context, ent.Message, ent.Metadata, mf)
+	case Debug:
+		m.debug(ent.Context, ent.Message, ent.Metadata, mf)
+	case Trace:
+		m.trace(ent.Context, ent.Message, ent.Metadata, mf)
+	default:
+		m.level(ent.Context, ent.Level, ent.Message, ent.Metadata, func(ctx context.Context, lvl Level, msg string, md map[string]any) {
+			ent.Context, ent.Level, ent.Message, ent.Metadata = ctx, lvl, msg, md
+
+			nxt(ent)
+		})
+	}
+}
+
+// Enabled checks if the underlying Logger writes entries at the level
+func (m *Modder) Enabled(ctx context.Context, lvl Level) bool {
+	return Enabled(m.logger, ctx, lvl)
+}
+
 // WithMods adds entry middleware
+// user-defined level entries go through the message mod
 func WithMods(l Logger, em ErrMod, mm MsgMod) Logger {
 	if em == nil && mm == nil {
 		return l
@@ -60,34 +165,43 @@ func WithMods(l Logger, em ErrMod, mm MsgMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  em,
+		panic:  em,
 		error:  em,
 		warn:   mm,
 		info:   mm,
 		debug:  mm,
+		trace:  mm,
+		level:  msgLvlMod(mm),
 	}
 }
 
-// WithErrMod adds fatal+error entry middleware
+// WithErrMod adds fatal+panic+error entry middleware
 func WithErrMod(l Logger, f ErrMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  f,
+		panic:  f,
 		error:  f,
 		warn:   NopMsgMod,
 		info:   NopMsgMod,
 		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
 	}
 }
 
-// WithMsgMod adds warn+info+debug entry middleware
+// WithMsgMod adds warn+info+debug+trace+user-defined level entry middleware
 func WithMsgMod(l Logger, f MsgMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  NopErrMod,
+		panic:  NopErrMod,
 		error:  NopErrMod,
 		warn:   f,
 		info:   f,
 		debug:  f,
+		trace:  f,
+		level:  msgLvlMod(f),
 	}
 }
 
@@ -96,10 +210,28 @@ func WithFatalMod(l Logger, f ErrMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  f,
+		panic:  NopErrMod,
 		error:  NopErrMod,
 		warn:   NopMsgMod,
 		info:   NopMsgMod,
 		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
+	}
+}
+
+// WithPanicMod adds panic entry middleware
+func WithPanicMod(l Logger, f ErrMod) Logger {
+	return &Modder{
+		logger: l,
+		fatal:  NopErrMod,
+		panic:  f,
+		error:  NopErrMod,
+		warn:   NopMsgMod,
+		info:   NopMsgMod,
+		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
 	}
 }
 
@@ -108,10 +240,13 @@ func WithErrorMod(l Logger, f ErrMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  NopErrMod,
+		panic:  NopErrMod,
 		error:  f,
 		warn:   NopMsgMod,
 		info:   NopMsgMod,
 		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
 	}
 }
 
@@ -120,10 +255,13 @@ func WithWarnMod(l Logger, f MsgMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  NopErrMod,
+		panic:  NopErrMod,
 		error:  NopErrMod,
 		warn:   f,
 		info:   NopMsgMod,
 		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
 	}
 }
 
@@ -132,10 +270,13 @@ func WithInfoMod(l Logger, f MsgMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  NopErrMod,
+		panic:  NopErrMod,
 		error:  NopErrMod,
 		warn:   NopMsgMod,
 		info:   f,
 		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
 	}
 }
 
@@ -144,10 +285,101 @@ func WithDebugMod(l Logger, f MsgMod) Logger {
 	return &Modder{
 		logger: l,
 		fatal:  NopErrMod,
+		panic:  NopErrMod,
 		error:  NopErrMod,
 		warn:   NopMsgMod,
 		info:   NopMsgMod,
 		debug:  f,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
+	}
+}
+
+// WithTraceMod adds trace entry middleware
+func WithTraceMod(l Logger, f MsgMod) Logger {
+	return &Modder{
+		logger: l,
+		fatal:  NopErrMod,
+		panic:  NopErrMod,
+		error:  NopErrMod,
+		warn:   NopMsgMod,
+		info:   NopMsgMod,
+		debug:  NopMsgMod,
+		trace:  f,
+		level:  NopLvlMod,
+	}
+}
+
+// WithLvlMod adds user-defined level entry middleware
+func WithLvlMod(l Logger, f LvlMod) Logger {
+	return &Modder{
+		logger: l,
+		fatal:  NopErrMod,
+		panic:  NopErrMod,
+		error:  NopErrMod,
+		warn:   NopMsgMod,
+		info:   NopMsgMod,
+		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  f,
+	}
+}
+
+// WithRouteMod adds entry middleware that can change the entry's level
+// rerouting fatal and panic entries to other levels means they won't exit or panic
+func WithRouteMod(l Logger, f RouteMod) Logger {
+	em := func(lvl Level) ErrMod {
+		return func(ctx context.Context, err error, md map[string]any, _ ErrFunc) {
+			f(ctx, lvl, err, "", md, l)
+		}
+	}
+
+	mm := func(lvl Level) MsgMod {
+		return func(ctx context.Context, msg string, md map[string]any, _ MsgFunc) {
+			f(ctx, lvl, nil, msg, md, l)
+		}
+	}
+
+	return &Modder{
+		logger: l,
+		fatal:  em(Fatal),
+		panic:  em(Panic),
+		error:  em(Error),
+		warn:   mm(Warn),
+		info:   mm(Info),
+		debug:  mm(Debug),
+		trace:  mm(Trace),
+		level: func(ctx context.Context, lvl Level, msg string, md map[string]any, _ LvlFunc) {
+			f(ctx, lvl, nil, msg, md, l)
+		},
+	}
+}
+
+// Route writes an entry to the Logger at any level
+// error entries rerouted to message levels use the error's message,
+// and message entries rerouted to error levels become errors
+func Route(lgr Logger, ctx context.Context, lvl Level, err error, msg string, md map[string]any) {
+	if !lvl.Builtin() || !Error.Allows(lvl) {
+		if msg == "" && err != nil {
+			msg = err.Error()
+		}
+
+		At(lgr, ctx, lvl, msg, md)
+
+		return
+	}
+
+	if err == nil {
+		err = mderr.New(msg, nil)
+	}
+
+	switch lvl {
+	case Fatal:
+		lgr.Fatal(ctx, err, md)
+	case Panic:
+		LogPanic(lgr, ctx, err, md)
+	default:
+		lgr.Error(ctx, err, md)
 	}
 }
 
@@ -160,3 +392,17 @@ func NopErrMod(ctx context.Context, err error, md map[string]any, f ErrFunc) {
 func NopMsgMod(ctx context.Context, msg string, md map[string]any, f MsgFunc) {
 	f(ctx, msg, md)
 }
+
+// NopLvlMod is a no-op user-defined level mod func
+func NopLvlMod(ctx context.Context, lvl Level, msg string, md map[string]any, f LvlFunc) {
+	f(ctx, lvl, msg, md)
+}
+
+// msgLvlMod runs user-defined level entries through a message mod
+func msgLvlMod(mm MsgMod) LvlMod {
+	return func(ctx context.Context, lvl Level, msg string, md map[string]any, f LvlFunc) {
+		mm(ctx, msg, md, func(ctx context.Context, msg string, md map[string]any) {
+			f(ctx, lvl, msg, md)
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package mdlog
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+)
+
+// Pipeline builds a single flattened Logger out of mods, ie
+//
+//	lgr = mdlog.NewPipeline().
+//		PersistedMetadata(map[string]any{"env": "prod"}).
+//		RequestID("").
+//		ErrorTrace("").
+//		Build(lgr)
+//
+// instead of one Modder per With* call, the built Logger uses one
+// metadata map per entry (the call's, or a new one if it's nil), writes the persisted metadata (merged once,
+// when built) and the request id, trace id and error trace into it,
+// then runs the ErrMod and MsgMod mods in the order they were added
+// message entries at levels the Logger doesn't write are dropped before any of that
+type Pipeline struct {
+	metadata   map[string]any
+	collisions CollisionConfig
+	steps      []step
+	errMods    []ErrMod
+	msgMods    []MsgMod
+}
+
+// step gets a metadata key and value to add to an entry, the key is "" if there's none
+type step func(ctx context.Context, err error) (string, any)
+
+// NewPipeline creates an empty Pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		metadata: map[string]any{},
+	}
+}
+
+// PersistedMetadata is WithPersistedMetadata
+// calling it more than once merges the metadata
+func (p *Pipeline) PersistedMetadata(pmd map[string]any) *Pipeline {
+	for key, val := range pmd {
+		p.metadata[key] = val
+	}
+
+	return p
+}
+
+// Collisions sets how the built Logger handles metadata key collisions,
+// the last call wins
+func (p *Pipeline) Collisions(cfg CollisionConfig) *Pipeline {
+	p.collisions = cfg
+
+	return p
+}
+
+// RequestID is WithRequestID
+func (p *Pipeline) RequestID(key string) *Pipeline {
+	if key == "" {
+		key = "request-id"
+	}
+
+	p.steps = append(p.steps, func(ctx context.Context, _ error) (string, any) {
+		rid := mdctx.RequestID(ctx)
+
+		if rid == "" {
+			return "", nil
+		}
+
+		return key, rid
+	})
+
+	return p
+}
+
+// TraceID is WithTraceID
+func (p *Pipeline) TraceID(tie TraceIDExtractor, key string) *Pipeline {
+	if tie == nil {
+		return p
+	}
+
+	if key == "" {
+		key = "trace-id"
+	}
+
+	p.steps = append(p.steps, func(ctx context.Context, _ error) (string, any) {
+		if ctx == nil {
+			return "", nil
+		}
+
+		tid := tie.TraceID(ctx)
+
+		if tid == "" {
+			return "", nil
+		}
+
+		return key, tid
+	})
+
+	return p
+}
+
+// ErrorTrace is WithErrorTrace
+func (p *Pipeline) ErrorTrace(key string) *Pipeline {
+	if key == "" {
+		key = "error-trace"
+	}
+
+	p.steps = append(p.steps, func(_ context.Context, err error) (string, any) {
+		if err == nil {
+			return "", nil
+		}
+
+		return key, mderr.Stack(err)
+	})
+
+	return p
+}
+
+// ErrMod is WithErrMod
+// the mod runs after the metadata is added
+func (p *Pipeline) ErrMod(em ErrMod) *Pipeline {
+	if em != nil {
+		p.errMods = append(p.errMods, em)
+	}
+
+	return p
+}
+
+// MsgMod is WithMsgMod
+// the mod runs after the metadata is added
+func (p *Pipeline) MsgMod(mm MsgMod) *Pipeline {
+	if mm != nil {
+		p.msgMods = append(p.msgMods, mm)
+	}
+
+	return p
+}
+
+// Build creates the flattened Logger on top of lgr
+// the Pipeline can be changed and built again without changing
+// the Loggers it already built
+func (p *Pipeline) Build(lgr Logger) Logger {
+	pmd := make(map[string]any, len(p.metadata))
+
+	for key, val := range p.metadata {
+		pmd[key] = val
+	}
+
+	pip := &pipeline{
+		logger:     lgr,
+		metadata:   pmd,
+		collisions: p.collisions,
+		steps:      append([]step{}, p.steps...),
+		errMods:    append([]ErrMod{}, p.errMods...),
+		msgMods:    append([]MsgMod{}, p.msgMods...),
+	}
+
+	pip.fatal = chainErr(p.errMods, lgr.Fatal)
+	pip.panic = chainErr(p.errMods, func(ctx context.Context, err error, md map[string]any) {
+		LogPanic(lgr, ctx, err, md)
+	})
+	pip.error = chainErr(p.errMods, lgr.Error)
+	pip.warn = chainMsg(p.msgMods, lgr.Warn)
+	pip.info = chainMsg(p.msgMods, lgr.Info)
+	pip.debug = chainMsg(p.msgMods, lgr.Debug)
+	pip.trace = chainMsg(p.msgMods, func(ctx context.Context, msg string, md map[string]any) {
+		LogTrace(lgr, ctx, msg, md)
+	})
+
+	return pip
+}
+
+// pipeline is a Logger built by a Pipeline
+// the mod chains are composed once, when built, so entries
+// don't make closures on the way to the underlying Logger
+type pipeline struct {
+	logger     Logger
+	metadata   map[string]any
+	collisions CollisionConfig
+	steps      []step
+	errMods    []ErrMod
+	msgMods    []MsgMod
+	fatal      ErrFunc
+	panic      ErrFunc
+	error      ErrFunc
+	warn       MsgFunc
+	info       MsgFunc
+	debug      MsgFunc
+	trace      MsgFunc
+}
+
+func (p *pipeline) Fatal(ctx context.Context, err error, md map[string]any) {
+	p.fatal(ctx, err, p.merge(ctx, err, md))
+}
+
+func (p *pipeline) Panic(ctx context.Context, err error, md map[string]any) {
+	p.panic(ctx, err, p.merge(ctx, err, md))
+}
+
+func (p *pipeline) Error(ctx context.Context, err error, md map[string]any) {
+	p.error(ctx, err, p.merge(ctx, err, md))
+}
+
+func (p *pipeline) Warn(ctx context.Context, msg string, md map[string]any) {
+	if !p.Enabled(ctx, Warn) {
+		return
+	}
+
+	p.warn(ctx, msg, p.merge(ctx, nil, md))
+}
+
+func (p *pipeline) Info(ctx context.Context, msg string, md map[string]any) {
+	if !p.Enabled(ctx, Info) {
+		return
+	}
+
+	p.info(ctx, msg, p.merge(ctx, nil, md))
+}
+
+func (p *pipeline) Debug(ctx context.Context, msg string, md map[string]any) {
+	if !p.Enabled(ctx, Debug) {
+		return
+	}
+
+	p.debug(ctx, msg, p.merge(ctx, nil, md))
+}
+
+func (p *pipeline) Trace(ctx context.Context, msg string, md map[string]any) {
+	if !p.Enabled(ctx, Trace) {
+		return
+	}
+
+	p.trace(ctx, msg, p.merge(ctx, nil, md))
+}
+
+// At writes an entry at any level
+// user-defined levels go through the message mods
+func (p *pipeline) At(ctx context.Context, lvl Level, msg string, md map[string]any) {
+	if lvl.Builtin() {
+		At(p, ctx, lvl, msg, md)
+
+		return
+	}
+
+	if !p.Enabled(ctx, lvl) {
+		return
+	}
+
+	f := func(ctx context.Context, msg string, md map[string]any) {
+		At(p.logger, ctx, lvl, msg, md)
+	}
+
+	chainMsg(p.msgMods, f)(ctx, msg, p.merge(ctx, nil, md))
+}
+
+// Log writes a whole entry
+// the entry's other fields, like the time and name, are kept
+func (p *pipeline) Log(ent Entry) {
+	if !(ent.Level.Builtin() && Error.Allows(ent.Level)) {
+		if !p.Enabled(ent.Context, ent.Level) {
+			return
+		}
+
+		chainMsg(p.msgMods, func(ctx context.Context, msg string, md map[string]any) {
+			ent.Context, ent.Message, ent.Metadata = ctx, msg, md
+
+			Log(p.logger, ent)
+		})(ent.Context, ent.Message, p.merge(ent.Context, nil, ent.Metadata))
+
+		return
+	}
+
+	if ent.Error == nil {
+		ent.Error = mderr.New(ent.Message, nil)
+	}
+
+	chainErr(p.errMods, func(ctx context.Context, err error, md map[string]any) {
+		ent.Context, ent.Error, ent.Metadata = ctx, err, md
+
+		Log(p.logger, ent)
+	})(ent.Context, ent.Error, p.merge(ent.Context, ent.Error, ent.Metadata))
+}
+
+// Enabled checks if the underlying Logger writes entries at the level
+func (p *pipeline) Enabled(ctx context.Context, lvl Level) bool {
+	return Enabled(p.logger, ctx, lvl)
+}
+
+// merge adds the persisted metadata and the steps' metadata to the entry's
+// metadata map, the call's map is written to like the With* mods do, so an
+// entry makes at most one map (none if the call passed one)
+func (p *pipeline) merge(ctx context.Context, err error, md map[string]any) map[string]any {
+	if len(p.metadata) == 0 && len(p.steps) == 0 {
+		return md
+	}
+
+	if md == nil {
+		md = make(map[string]any, len(p.metadata)+len(p.steps))
+	}
+
+	for key, val := range p.metadata {
+		md = p.collisions.Add(md, key, val)
+	}
+
+	for _, stp := range p.steps {
+		key, val := stp(ctx, err)
+
+		if key != "" {
+			md = p.collisions.Add(md, key, val)
+		}
+	}
+
+	return md
+}
+
+// chainErr composes error mods into one func, first mod first
+func chainErr(ems []ErrMod, f ErrFunc) ErrFunc {
+	for i := len(ems) - 1; i >= 0; i-- {
+		em := ems[i]
+		nxt := f
+
+		f = func(ctx context.Context, err error, md map[string]any) {
+			em(ctx, err, md, nxt)
+		}
+	}
+
+	return f
+}
+
+// chainMsg composes message mods into one func, first mod first
+func chainMsg(mms []MsgMod, f MsgFunc) MsgFunc {
+	for i := len(mms) - 1; i >= 0; i-- {
+		mm := mms[i]
+		nxt := f
+
+		f = func(ctx context.Context, msg string, md map[string]any) {
+			mm(ctx, msg, md, nxt)
+		}
+	}
+
+	return f
+}
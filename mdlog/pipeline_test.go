@@ -0,0 +1,73 @@
+package mdlog_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	var act []map[string]any
+	var msgs []string
+
+	ef := func(_ context.Context, err error, md map[string]any) {
+		msgs = append(msgs, err.Error())
+		act = append(act, md)
+	}
+
+	mf := func(_ context.Context, msg string, md map[string]any) {
+		msgs = append(msgs, msg)
+		act = append(act, md)
+	}
+
+	lgr := &TestLogger{
+		ErrorFunc: ef,
+		InfoFunc:  mf,
+		DebugFunc: mf,
+		AtFunc: func(_ context.Context, lvl mdlog.Level, msg string, md map[string]any) {
+			assert.Equal(t, mdlog.Level(35), lvl)
+
+			mf(nil, msg, md)
+		},
+		EnabledFunc: func(_ context.Context, lvl mdlog.Level) bool {
+			return mdlog.Info.Allows(lvl)
+		},
+	}
+
+	pip := mdlog.NewPipeline().
+		PersistedMetadata(map[string]any{"env": "test"}).
+		PersistedMetadata(map[string]any{"app": "md"}).
+		RequestID("").
+		TraceID(mdlog.TraceIDFunc(func(context.Context) string {
+			return "tid"
+		}), "").
+		ErrorTrace("").
+		MsgMod(func(ctx context.Context, msg string, md map[string]any, f mdlog.MsgFunc) {
+			f(ctx, "first "+msg, md)
+		}).
+		MsgMod(func(ctx context.Context, msg string, md map[string]any, f mdlog.MsgFunc) {
+			f(ctx, "second "+msg, md)
+		}).
+		ErrMod(func(ctx context.Context, err error, md map[string]any, f mdlog.ErrFunc) {
+			f(ctx, mderr.Wrap(err, "modded", nil), md)
+		})
+
+	out := pip.Build(lgr)
+	ctx := mdctx.WithRequestID(context.Background(), "rid")
+	cmd := map[string]any{"foo": "bar"}
+
+	out.Info(ctx, "info", cmd)
+	out.Debug(ctx, "debug", nil)
+	out.Error(ctx, mderr.New("error", nil), nil)
+	out.(mdlog.LevelLogger).At(ctx, mdlog.Level(35), "custom", nil)
+
+	assert.Equal(t, []string{"second first info", "modded: error", "second first custom"}, msgs)
+	assert.Len(t, act, 3)
+	assert.Equal(t, map[string]any{"foo": "bar", "env": "test", "app": "md", "request-id": "rid", "trace-id": "tid"}, act[0])
+	assert.Contains(t, act[1], "error-trace")
+	assert.Equal(t, "rid", act[2]["request-id"])
+	assert.False(t, mdlog.Enabled(out, ctx, mdlog.Debug))
+}
@@ -0,0 +1,295 @@
+package mdlog
+
+import (
+	"context"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"sync"
+	"time"
+)
+
+// Report is an error entry sent to an error tracker by a Reporter
+type Report struct {
+	Time  time.Time
+	Level Level
+	Error error
+	// Stack is the error's mderr.Stack
+	Stack []*struct {
+		Message  string         `json:"message"`
+		Metadata map[string]any `json:"metadata"`
+	}
+	RequestID string
+	// Metadata is a copy of the entry's metadata with lazy values computed
+	Metadata map[string]any
+}
+
+// Transport sends batches of reports to an error tracker
+type Transport interface {
+	Send(context.Context, []*Report) error
+}
+
+// TransportFunc is a func that implements Transport
+type TransportFunc func(context.Context, []*Report) error
+
+// Send calls the func
+func (f TransportFunc) Send(ctx context.Context, rps []*Report) error {
+	return f(ctx, rps)
+}
+
+// ReporterConfig is the Reporter config
+// zero values use the defaults
+type ReporterConfig struct {
+	// BatchSize is the max reports sent at once, defaults to 10
+	BatchSize int
+	// Interval is the max time a report waits for a batch to fill, defaults to 5s
+	Interval time.Duration
+	// Buffer is the max reports waiting to be sent, defaults to 1000
+	// reports are dropped when it's full so logging never blocks
+	Buffer int
+	// Timeout is the max time a batch takes to send, defaults to 10s
+	Timeout time.Duration
+	// OnError is called when a batch fails to send or a report is dropped
+	OnError func(error)
+}
+
+// Reporter batches error entries and sends them through a Transport
+// see WithReporter
+type Reporter struct {
+	transport Transport
+	config    ReporterConfig
+	reports   chan *Report
+	flushes   chan chan error
+	done      chan struct{}
+	wait      sync.WaitGroup
+	mutex     sync.RWMutex
+	closed    bool
+}
+
+// NewReporter creates a Reporter and starts sending batches in the background
+// call Close to send the remaining reports and stop
+func NewReporter(tpt Transport, cfg ReporterConfig) *Reporter {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1000
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	if cfg.OnError == nil {
+		cfg.OnError = func(error) {}
+	}
+
+	rep := &Reporter{
+		transport: tpt,
+		config:    cfg,
+		reports:   make(chan *Report, cfg.Buffer),
+		flushes:   make(chan chan error),
+		done:      make(chan struct{}),
+	}
+
+	rep.wait.Add(1)
+
+	go rep.run()
+
+	return rep
+}
+
+// Report queues an error entry to be sent
+func (r *Reporter) Report(ctx context.Context, lvl Level, err error, emd map[string]any) {
+	if err == nil {
+		return
+	}
+
+	rpt := &Report{
+		Time:      time.Now(),
+		Level:     lvl,
+		Error:     err,
+		Stack:     mderr.Stack(err),
+		RequestID: mdctx.RequestID(ctx),
+		Metadata:  md.Resolve(emd),
+	}
+
+	// the read lock keeps Close from closing the queue until the report is
+	// queued, so it's either queued and sent by Close, or rejected
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		r.config.OnError(mderr.New("reporter is closed", map[string]any{
+			"error": err.Error(),
+		}))
+
+		return
+	}
+
+	select {
+	case r.reports <- rpt:
+	default:
+		r.config.OnError(mderr.New("reporter buffer is full", map[string]any{
+			"error":  err.Error(),
+			"buffer": r.config.Buffer,
+		}))
+	}
+}
+
+// Flush sends the queued reports and waits for them to be sent
+func (r *Reporter) Flush(ctx context.Context) error {
+	res := make(chan error, 1)
+
+	select {
+	case <-r.done:
+		return mderr.New("reporter is closed", nil)
+	case <-ctx.Done():
+		return ctx.Err()
+	case r.flushes <- res:
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-res:
+		return err
+	}
+}
+
+// Close sends the queued reports and stops the Reporter
+// reports after it are rejected with OnError
+func (r *Reporter) Close() error {
+	r.mutex.Lock()
+
+	if !r.closed {
+		r.closed = true
+
+		close(r.done)
+	}
+
+	r.mutex.Unlock()
+
+	r.wait.Wait()
+
+	return nil
+}
+
+func (r *Reporter) run() {
+	defer r.wait.Done()
+
+	tkr := time.NewTicker(r.config.Interval)
+	bat := make([]*Report, 0, r.config.BatchSize)
+
+	defer tkr.Stop()
+
+	send := func() error {
+		if len(bat) == 0 {
+			return nil
+		}
+
+		ctx, ccl := context.WithTimeout(context.Background(), r.config.Timeout)
+		err := r.transport.Send(ctx, bat)
+
+		ccl()
+
+		if err != nil {
+			err = mderr.Wrap(err, "failed to send reports", map[string]any{
+				"reports": len(bat),
+			})
+
+			r.config.OnError(err)
+		}
+
+		bat = make([]*Report, 0, r.config.BatchSize)
+
+		return err
+	}
+
+	// drain moves the queued reports into batches, sending the full ones
+	drain := func() error {
+		var err error
+
+		for {
+			select {
+			case rpt := <-r.reports:
+				bat = append(bat, rpt)
+
+				if len(bat) >= r.config.BatchSize {
+					if sen := send(); sen != nil && err == nil {
+						err = sen
+					}
+				}
+			default:
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-r.done:
+			_ = drain()
+			_ = send()
+
+			return
+		case res := <-r.flushes:
+			err := drain()
+
+			if sen := send(); sen != nil && err == nil {
+				err = sen
+			}
+
+			res <- err
+		case rpt := <-r.reports:
+			bat = append(bat, rpt)
+
+			if len(bat) >= r.config.BatchSize {
+				_ = send()
+			}
+		case <-tkr.C:
+			_ = send()
+		}
+	}
+}
+
+// WithReporter applies error reporting logger middleware
+// fatal, panic and error entries are sent to the Reporter,
+// then passed on to the Logger
+// fatal entries are flushed before being passed on since the process exits
+// apply it first, on top of the backend, so the reports get the
+// metadata added by the other mods
+func WithReporter(lgr Logger, rep *Reporter) Logger {
+	mod := func(lvl Level) ErrMod {
+		return func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
+			rep.Report(ctx, lvl, err, md)
+
+			if lvl == Fatal {
+				fcx, ccl := context.WithTimeout(context.Background(), rep.config.Timeout)
+
+				_ = rep.Flush(fcx)
+
+				ccl()
+			}
+
+			f(ctx, err, md)
+		}
+	}
+
+	return &Modder{
+		logger: lgr,
+		fatal:  mod(Fatal),
+		panic:  mod(Panic),
+		error:  mod(Error),
+		warn:   NopMsgMod,
+		info:   NopMsgMod,
+		debug:  NopMsgMod,
+		trace:  NopMsgMod,
+		level:  NopLvlMod,
+	}
+}
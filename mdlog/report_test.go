@@ -0,0 +1,155 @@
+package mdlog_test
+
+import (
+	"context"
+	"github.com/chaseisabelle/md"
+	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
+	"github.com/chaseisabelle/md/mdlog"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithReporter(t *testing.T) {
+	var mtx sync.Mutex
+	var bts [][]*mdlog.Report
+
+	rep := mdlog.NewReporter(mdlog.TransportFunc(func(_ context.Context, rps []*mdlog.Report) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		bts = append(bts, rps)
+
+		return nil
+	}), mdlog.ReporterConfig{
+		BatchSize: 2,
+		Interval:  time.Hour,
+	})
+
+	defer rep.Close()
+
+	nop := func(context.Context, error, map[string]any) {}
+	rid := uuid.New().String()
+	ctx := mdctx.WithRequestID(context.Background(), rid)
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		ErrorFunc: nop,
+		PanicFunc: nop,
+		WarnFunc:  func(context.Context, string, map[string]any) {},
+	}
+
+	lgr = mdlog.WithReporter(lgr, rep)
+	lgr = mdlog.WithPersistedMetadata(lgr, map[string]any{
+		"app": "test",
+	})
+
+	err := mderr.Wrap(mderr.New("root error", nil), "surface error", nil)
+
+	lgr.Error(ctx, err, md.MD{
+		"lazy": md.Lazy(func() any {
+			return "value"
+		}),
+	})
+	lgr.Warn(ctx, "not reported", nil)
+	mdlog.LogPanic(lgr, ctx, err, nil)
+	lgr.Error(ctx, err, nil)
+
+	assert.NoError(t, rep.Flush(context.Background()))
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	assert.Len(t, bts, 2)
+	assert.Len(t, bts[0], 2)
+	assert.Len(t, bts[1], 1)
+
+	rpt := bts[0][0]
+
+	assert.Equal(t, mdlog.Error, rpt.Level)
+	assert.Equal(t, err, rpt.Error)
+	assert.Equal(t, rid, rpt.RequestID)
+	assert.Equal(t, map[string]any{"app": "test", "lazy": "value"}, rpt.Metadata)
+	assert.Len(t, rpt.Stack, 2)
+	assert.Equal(t, "surface error", rpt.Stack[0].Message)
+	assert.Equal(t, mdlog.Panic, bts[0][1].Level)
+}
+
+func TestReporterInterval(t *testing.T) {
+	snt := make(chan []*mdlog.Report, 1)
+
+	rep := mdlog.NewReporter(mdlog.TransportFunc(func(_ context.Context, rps []*mdlog.Report) error {
+		snt <- rps
+
+		return nil
+	}), mdlog.ReporterConfig{
+		Interval: 10 * time.Millisecond,
+	})
+
+	rep.Report(context.Background(), mdlog.Error, mderr.New("error", nil), nil)
+
+	select {
+	case rps := <-snt:
+		assert.Len(t, rps, 1)
+	case <-time.After(time.Second):
+		assert.Fail(t, "batch was not sent")
+	}
+
+	assert.NoError(t, rep.Close())
+	assert.Error(t, rep.Flush(context.Background()))
+}
+
+func TestReporterClose(t *testing.T) {
+	var mtx sync.Mutex
+	var snt, rej int
+
+	rep := mdlog.NewReporter(mdlog.TransportFunc(func(_ context.Context, rps []*mdlog.Report) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+
+		snt += len(rps)
+
+		return nil
+	}), mdlog.ReporterConfig{
+		BatchSize: 5,
+		Interval:  time.Hour,
+		Buffer:    10000,
+		OnError: func(error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			rej++
+		},
+	})
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				rep.Report(context.Background(), mdlog.Error, mderr.New("error", nil), nil)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond)
+
+	assert.NoError(t, rep.Close())
+
+	wg.Wait()
+	rep.Report(context.Background(), mdlog.Error, mderr.New("error", nil), nil)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	assert.Equal(t, 1001, snt+rej)
+	assert.Greater(t, rej, 0)
+}
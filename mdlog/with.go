@@ -2,7 +2,6 @@ package mdlog
 
 import (
 	"context"
-	"github.com/aws/aws-xray-sdk-go/xray"
 	"github.com/chaseisabelle/md/mdctx"
 	"github.com/chaseisabelle/md/mderr"
 )
@@ -11,18 +10,21 @@ import (
 // all log entries
 // useful for adding things like "env" or "app-name" that would be
 // in all log entries
-func WithPersistedMetadata(lgr Logger, pmd map[string]any) Logger {
+// keys the entry already has are handled per the optional CollisionConfig
+func WithPersistedMetadata(lgr Logger, pmd map[string]any, cls ...CollisionConfig) Logger {
 	if pmd == nil || len(pmd) == 0 {
 		return lgr
 	}
 
+	col := collisions(cls)
+
 	mod := func(md map[string]any) map[string]any {
 		if md == nil {
-			return pmd
+			md = make(map[string]any, len(pmd))
 		}
 
 		for key, val := range pmd {
-			md[key] = val
+			md = col.Add(md, key, val)
 		}
 
 		return md
@@ -39,18 +41,17 @@ func WithPersistedMetadata(lgr Logger, pmd map[string]any) Logger {
 // the returned Logger will inject the error trace into the
 // metadata payload from the context with the key
 // if key == "" then "error-trace" is used
-func WithErrorTrace(lgr Logger, key string) Logger {
+// a key the entry already has is handled per the optional CollisionConfig
+func WithErrorTrace(lgr Logger, key string, cls ...CollisionConfig) Logger {
 	if key == "" {
 		key = "error-trace"
 	}
 
+	col := collisions(cls)
+
 	return WithErrMod(lgr, func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
 		if err != nil {
-			if md == nil {
-				md = map[string]any{}
-			}
-
-			md[key] = mderr.Stack(err)
+			md = col.Add(md, key, mderr.Stack(err))
 		}
 
 		f(ctx, err, md)
@@ -61,11 +62,14 @@ func WithErrorTrace(lgr Logger, key string) Logger {
 // the returned Logger will inject the request id into the
 // metadata payload from the context with the key
 // if key == "" then "request-id" is used
-func WithRequestID(lgr Logger, key string) Logger {
+// a key the entry already has is handled per the optional CollisionConfig
+func WithRequestID(lgr Logger, key string, cls ...CollisionConfig) Logger {
 	if key == "" {
 		key = "request-id"
 	}
 
+	col := collisions(cls)
+
 	mod := func(ctx context.Context, md map[string]any) map[string]any {
 		if ctx == nil {
 			return md
@@ -77,13 +81,7 @@ func WithRequestID(lgr Logger, key string) Logger {
 			return md
 		}
 
-		if md == nil {
-			md = map[string]any{}
-		}
-
-		md[key] = rid
-
-		return md
+		return col.Add(md, key, rid)
 	}
 
 	return WithMods(lgr, func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
@@ -93,33 +91,48 @@ func WithRequestID(lgr Logger, key string) Logger {
 	})
 }
 
-// WithAWSXRayTraceID applies aws xray logger middleware
-// the returned Logger will inject aws xray's trace id into the
-// metadata payload with the key
+// TraceIDExtractor gets a trace id from a context
+// implement this to plug a tracing system into WithTraceID
+type TraceIDExtractor interface {
+	TraceID(context.Context) string
+}
+
+// TraceIDFunc is a func that implements TraceIDExtractor
+type TraceIDFunc func(context.Context) string
+
+// TraceID calls the func
+func (f TraceIDFunc) TraceID(ctx context.Context) string {
+	return f(ctx)
+}
+
+// WithTraceID applies trace id logger middleware
+// the returned Logger will inject the trace id from the
+// extractor into the metadata payload with the key
 // if key == "" then "trace-id" is used
-func WithAWSXRayTraceID(lgr Logger, key string) Logger {
+// a key the entry already has is handled per the optional CollisionConfig
+func WithTraceID(lgr Logger, tie TraceIDExtractor, key string, cls ...CollisionConfig) Logger {
+	if tie == nil {
+		return lgr
+	}
+
 	if key == "" {
 		key = "trace-id"
 	}
 
+	col := collisions(cls)
+
 	mod := func(ctx context.Context, md map[string]any) map[string]any {
 		if ctx == nil {
 			return md
 		}
 
-		tid := xray.TraceID(ctx)
+		tid := tie.TraceID(ctx)
 
 		if tid == "" {
 			return md
 		}
 
-		if md == nil {
-			md = map[string]any{}
-		}
-
-		md[key] = tid
-
-		return md
+		return col.Add(md, key, tid)
 	}
 
 	return WithMods(lgr, func(ctx context.Context, err error, md map[string]any, f ErrFunc) {
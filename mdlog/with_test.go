@@ -3,6 +3,7 @@ package mdlog_test
 import (
 	"context"
 	"github.com/chaseisabelle/md/mdctx"
+	"github.com/chaseisabelle/md/mderr"
 	"github.com/chaseisabelle/md/mdlog"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -29,23 +30,33 @@ func TestWithPersistedMetadata(t *testing.T) {
 		f(md)
 	}
 
+	lf := func(_ context.Context, _ mdlog.Level, _ string, md map[string]any) {
+		f(md)
+	}
+
 	var lgr mdlog.Logger
 
 	lgr = &TestLogger{
 		FatalFunc: ef,
+		PanicFunc: ef,
 		ErrorFunc: ef,
 		WarnFunc:  mf,
 		InfoFunc:  mf,
 		DebugFunc: mf,
+		TraceFunc: mf,
+		AtFunc:    lf,
 	}
 
 	lgr = mdlog.WithPersistedMetadata(lgr, md)
 
 	lgr.Fatal(nil, nil, nil)
+	mdlog.LogPanic(lgr, nil, nil, nil)
 	lgr.Error(nil, nil, nil)
 	lgr.Warn(nil, "", nil)
 	lgr.Info(nil, "", nil)
 	lgr.Debug(nil, "", nil)
+	mdlog.LogTrace(lgr, nil, "", nil)
+	lgr.(mdlog.LevelLogger).At(nil, mdlog.Level(35), "", nil)
 }
 
 func TestWithRequestID(t *testing.T) {
@@ -66,22 +77,126 @@ func TestWithRequestID(t *testing.T) {
 		f(md)
 	}
 
+	lf := func(_ context.Context, _ mdlog.Level, _ string, md map[string]any) {
+		f(md)
+	}
+
 	var lgr mdlog.Logger
 
 	lgr = &TestLogger{
 		FatalFunc: ef,
+		PanicFunc: ef,
 		ErrorFunc: ef,
 		WarnFunc:  mf,
 		InfoFunc:  mf,
 		DebugFunc: mf,
+		TraceFunc: mf,
+		AtFunc:    lf,
 	}
 
 	ctx := mdctx.WithRequestID(context.Background(), exp)
 	lgr = mdlog.WithRequestID(lgr, "")
 
 	lgr.Fatal(ctx, nil, nil)
+	mdlog.LogPanic(lgr, ctx, nil, nil)
 	lgr.Error(ctx, nil, nil)
 	lgr.Warn(ctx, "", nil)
 	lgr.Info(ctx, "", nil)
 	lgr.Debug(ctx, "", nil)
+	mdlog.LogTrace(lgr, ctx, "", nil)
+	lgr.(mdlog.LevelLogger).At(ctx, mdlog.Level(35), "", nil)
+}
+
+func TestWithTraceID(t *testing.T) {
+	exp := uuid.New().String()
+
+	f := func(md map[string]any) {
+		act, ok := md["trace-id"]
+
+		assert.True(t, ok)
+		assert.Equal(t, exp, act)
+	}
+
+	ef := func(_ context.Context, _ error, md map[string]any) {
+		f(md)
+	}
+
+	mf := func(_ context.Context, _ string, md map[string]any) {
+		f(md)
+	}
+
+	lf := func(_ context.Context, _ mdlog.Level, _ string, md map[string]any) {
+		f(md)
+	}
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		FatalFunc: ef,
+		PanicFunc: ef,
+		ErrorFunc: ef,
+		WarnFunc:  mf,
+		InfoFunc:  mf,
+		DebugFunc: mf,
+		TraceFunc: mf,
+		AtFunc:    lf,
+	}
+
+	ctx := context.Background()
+	lgr = mdlog.WithTraceID(lgr, mdlog.TraceIDFunc(func(context.Context) string {
+		return exp
+	}), "")
+
+	lgr.Fatal(ctx, nil, nil)
+	mdlog.LogPanic(lgr, ctx, nil, nil)
+	lgr.Error(ctx, nil, nil)
+	lgr.Warn(ctx, "", nil)
+	lgr.Info(ctx, "", nil)
+	lgr.Debug(ctx, "", nil)
+	mdlog.LogTrace(lgr, ctx, "", nil)
+	lgr.(mdlog.LevelLogger).At(ctx, mdlog.Level(35), "", nil)
+}
+
+func TestWithRouteMod(t *testing.T) {
+	var act []string
+
+	ef := func(lvl mdlog.Level) mdlog.ErrFunc {
+		return func(_ context.Context, err error, _ map[string]any) {
+			act = append(act, lvl.String()+":"+err.Error())
+		}
+	}
+
+	mf := func(lvl mdlog.Level) mdlog.MsgFunc {
+		return func(_ context.Context, msg string, _ map[string]any) {
+			act = append(act, lvl.String()+":"+msg)
+		}
+	}
+
+	var lgr mdlog.Logger
+
+	lgr = &TestLogger{
+		ErrorFunc: ef(mdlog.Error),
+		WarnFunc:  mf(mdlog.Warn),
+		InfoFunc:  mf(mdlog.Info),
+	}
+
+	lgr = mdlog.WithRouteMod(lgr, func(ctx context.Context, lvl mdlog.Level, err error, msg string, md map[string]any, lgr mdlog.Logger) {
+		switch {
+		case err != nil:
+			lvl = mdlog.Warn
+		case md["escalate"] == true:
+			lvl = mdlog.Error
+		case msg == "drop":
+			return
+		}
+
+		mdlog.Route(lgr, ctx, lvl, err, msg, md)
+	})
+
+	lgr.Error(nil, mderr.New("downgraded", nil), nil)
+	lgr.Warn(nil, "escalated", map[string]any{"escalate": true})
+	lgr.Info(nil, "drop", nil)
+	lgr.Info(nil, "info", nil)
+
+	assert.Equal(t, []string{"warn:downgraded", "error:escalated", "info:info"}, act)
 }